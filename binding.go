@@ -0,0 +1,260 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotech-labs/core/errors"
+	"golang.org/x/xerrors"
+)
+
+// FieldError is the error of a single request field.
+type FieldError struct {
+	Field  string `json:"field"`
+	Source string `json:"source,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func (e *FieldError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("field=%v, reason=%v", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("field=%v, source=%v, reason=%v", e.Field, e.Source, e.Reason)
+}
+
+// FieldErrors is the list of every offending field of a request.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// fieldErrorsBody renders the error with the list of field errors.
+type fieldErrorsBody struct {
+	errors.Error
+	Errors FieldErrors
+}
+
+// MarshalJSON adds the errors member to the JSON object of the error.
+func (b *fieldErrorsBody) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{})
+	if data, err := json.Marshal(b.Error); err == nil {
+		_ = json.Unmarshal(data, &members)
+	}
+	if len(members) == 0 {
+		members["message"] = b.Error.Error()
+	}
+	members["errors"] = b.Errors
+	return json.Marshal(members)
+}
+
+// fieldErrorsOf returns the FieldErrors in the wrapped chain of the error.
+func fieldErrorsOf(err error) FieldErrors {
+	var errs FieldErrors
+	if err != nil && xerrors.As(err, &errs) {
+		return errs
+	}
+	return nil
+}
+
+// HasParamTags reports whether the struct has fields bound from path, query, header or form.
+func HasParamTags(obj interface{}) bool {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && HasParamTags(reflect.New(f.Type).Interface()) {
+			return true
+		}
+		for _, source := range paramSources {
+			if _, ok := f.Tag.Lookup(source); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BindParams fills the fields of the struct tagged with path:"name", query:"name", header:"Name" and form:"name"
+// from the request. Form fields of type *FormFile and []*FormFile are bound to the uploaded files. Fields without a value in the request are left unchanged.
+// Time fields are parsed with the layout tag, RFC 3339 by default.
+func BindParams(req Request, obj interface{}) FieldErrors {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return bindParams(req, v.Elem())
+}
+
+func bindParams(req Request, v reflect.Value) FieldErrors {
+	var errs FieldErrors
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous && fv.Kind() == reflect.Struct {
+			errs = append(errs, bindParams(req, fv)...)
+			continue
+		}
+		for _, source := range paramSources {
+			name, ok := f.Tag.Lookup(source)
+			if !ok || name == "" || name == "-" {
+				continue
+			}
+			if source == "form" && (f.Type == formFileType || f.Type == reflect.SliceOf(formFileType)) {
+				bindFormFiles(req, fv, name)
+				continue
+			}
+			values := paramValues(req, source, name)
+			if len(values) == 0 || len(values) == 1 && values[0] == "" {
+				continue
+			}
+			if err := setField(fv, values, f.Tag.Get("layout")); err != nil {
+				errs = append(errs, &FieldError{
+					Field:  name,
+					Source: source,
+					Reason: err.Error(),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+func paramValues(req Request, source, name string) []string {
+	switch source {
+	case "path":
+		if value := req.PathParameter(name); value != "" {
+			return []string{value}
+		}
+	case "query":
+		return req.QueryValues(name)
+	case "header":
+		return req.Header(name)
+	case "form":
+		return req.FormValues(name)
+	}
+	return nil
+}
+
+func bindFormFiles(req Request, fv reflect.Value, name string) {
+	var files []*FormFile
+	_ = req.MultipartFiles(func(file *FormFile) error {
+		if file.Field == name {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if len(files) == 0 {
+		return
+	}
+	if fv.Kind() == reflect.Ptr {
+		fv.Set(reflect.ValueOf(files[0]))
+		return
+	}
+	fv.Set(reflect.ValueOf(files))
+}
+
+func setField(fv reflect.Value, values []string, layout string) error {
+	if fv.Kind() == reflect.Ptr {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setField(ptr.Elem(), values, layout); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setField(slice.Index(i), []string{value}, layout); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setValue(fv, values[0], layout)
+}
+
+func setValue(fv reflect.Value, value string, layout string) error {
+	if fv.CanAddr() {
+		if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if _, isTime := fv.Interface().(time.Time); !isTime {
+				return u.UnmarshalText([]byte(value))
+			}
+		}
+	}
+	switch fv.Interface().(type) {
+	case time.Time:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return fmt.Errorf("invalid time %q: layout=%v", value, layout)
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", value)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %v %q", fv.Kind(), value)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %v %q", fv.Kind(), value)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %v %q", fv.Kind(), value)
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %v", fv.Type())
+	}
+	return nil
+}
+
+var (
+	paramSources = []string{"path", "query", "header", "form"}
+	formFileType = reflect.TypeOf((*FormFile)(nil))
+)
@@ -0,0 +1,42 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+)
+
+func TestHasParamTags(t *testing.T) {
+	type Embedded struct {
+		ID int `path:"id"`
+	}
+	for _, tc := range []struct {
+		name     string
+		obj      interface{}
+		expected bool
+	}{
+		{name: "path tag", obj: &struct {
+			ID int `path:"id"`
+		}{}, expected: true},
+		{name: "embedded struct", obj: &struct{ Embedded }{}, expected: true},
+		{name: "json only", obj: &struct {
+			ID int `json:"id"`
+		}{}, expected: false},
+		{name: "not struct", obj: &[]int{}, expected: false},
+		{name: "nil", obj: nil, expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, HasParamTags(tc.obj))
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	errs := FieldErrors{
+		{Field: "id", Source: "path", Reason: "invalid int"},
+		{Field: "name", Reason: "required"},
+	}
+	assert.Equal(t, "field=id, source=path, reason=invalid int; field=name, reason=required", errs.Error())
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Chain is an ordered list of middlewares. The first middleware is the outermost one.
+type Chain struct {
+	entries []chainEntry
+}
+
+type chainEntry struct {
+	name       string
+	middleware MiddlewareFunc
+}
+
+// NewChain returns a chain of the middlewares.
+func NewChain(middlewares ...MiddlewareFunc) *Chain {
+	return new(Chain).Append(middlewares...)
+}
+
+// Use returns a new chain with the middleware added to the end with the name.
+func (c *Chain) Use(name string, middleware MiddlewareFunc) *Chain {
+	entries := make([]chainEntry, 0, len(c.entries)+1)
+	entries = append(entries, c.entries...)
+	entries = append(entries, chainEntry{
+		name:       name,
+		middleware: middleware,
+	})
+	return &Chain{entries: entries}
+}
+
+// Append returns a new chain with the middlewares added to the end.
+func (c *Chain) Append(middlewares ...MiddlewareFunc) *Chain {
+	entries := make([]chainEntry, 0, len(c.entries)+len(middlewares))
+	entries = append(entries, c.entries...)
+	entries = append(entries, newChainEntries(middlewares)...)
+	return &Chain{entries: entries}
+}
+
+// When returns a new chain with the middleware added to the end, applied only to
+// requests that satisfy the condition. It is named after the middleware in Names.
+func (c *Chain) When(cond func(Request) bool, middleware MiddlewareFunc) *Chain {
+	return c.Use(middlewareName(middleware), When(cond, middleware))
+}
+
+// Prepend returns a new chain with the middlewares added to the beginning.
+func (c *Chain) Prepend(middlewares ...MiddlewareFunc) *Chain {
+	entries := make([]chainEntry, 0, len(c.entries)+len(middlewares))
+	entries = append(entries, newChainEntries(middlewares)...)
+	entries = append(entries, c.entries...)
+	return &Chain{entries: entries}
+}
+
+// Then wraps the handler function with the middlewares of the chain.
+func (c *Chain) Then(h HandlerFunc) HandlerFunc {
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		h = c.entries[i].middleware(h)
+	}
+	return h
+}
+
+// Middlewares returns the middlewares of the chain in order.
+func (c *Chain) Middlewares() []MiddlewareFunc {
+	middlewares := make([]MiddlewareFunc, len(c.entries))
+	for i, entry := range c.entries {
+		middlewares[i] = entry.middleware
+	}
+	return middlewares
+}
+
+// Names returns the names of the middlewares in order.
+// Middlewares added without a name are named after their package, e.g. "accesslog".
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.entries))
+	for i, entry := range c.entries {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// When applies the middleware only to requests that satisfy the condition.
+func When(cond func(Request) bool, middleware MiddlewareFunc) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		wrapped := middleware(next)
+		return func(ctx context.Context, req Request) Response {
+			if cond(req) {
+				return wrapped(ctx, req)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// ForPaths applies the middleware only to the paths.
+// A path ending with "*" matches every path that has the preceding prefix.
+func ForPaths(middleware MiddlewareFunc, paths ...string) MiddlewareFunc {
+	return When(func(req Request) bool {
+		return matchPath(paths, req.Path())
+	}, middleware)
+}
+
+// ForMethods applies the middleware only to the http methods.
+func ForMethods(middleware MiddlewareFunc, methods ...string) MiddlewareFunc {
+	return When(func(req Request) bool {
+		for _, method := range methods {
+			if strings.EqualFold(method, req.Method()) {
+				return true
+			}
+		}
+		return false
+	}, middleware)
+}
+
+func matchPath(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if pattern == path {
+			return true
+		}
+	}
+	return false
+}
+
+func newChainEntries(middlewares []MiddlewareFunc) []chainEntry {
+	entries := make([]chainEntry, len(middlewares))
+	for i, middleware := range middlewares {
+		entries[i] = chainEntry{
+			name:       middlewareName(middleware),
+			middleware: middleware,
+		}
+	}
+	return entries
+}
+
+// middlewareName returns the package name of the function,
+// e.g. "github.com/gotech-labs/api/middleware/accesslog.(*accessLog).Middleware.func1" -> "accesslog".
+func middlewareName(middleware MiddlewareFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(middleware).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
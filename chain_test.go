@@ -0,0 +1,125 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+	apitest "github.com/gotech-labs/api/http/testing"
+	"github.com/gotech-labs/api/middleware/health"
+)
+
+func TestChain(t *testing.T) {
+	var (
+		calls      []string
+		middleware = func(name string) MiddlewareFunc {
+			return func(next HandlerFunc) HandlerFunc {
+				return func(ctx context.Context, req Request) Response {
+					calls = append(calls, name)
+					return next(ctx, req)
+				}
+			}
+		}
+		handler = func(ctx context.Context, req Request) Response {
+			calls = append(calls, "handler")
+			return OK("ok")
+		}
+		req = apitest.RequestBuilder{
+			Method: http.MethodGet,
+			Path:   "/events",
+		}.Build()
+	)
+
+	t.Run("ordering", func(t *testing.T) {
+		calls = nil
+		chain := NewChain().
+			Use("second", middleware("second")).
+			Use("third", middleware("third"))
+		chain = chain.Prepend(middleware("first")).Append(middleware("fourth"))
+
+		resp := chain.Then(handler)(context.Background(), req)
+		assert.Equal(t, http.StatusOK, resp.Status())
+		assert.Equal(t, []string{"first", "second", "third", "fourth", "handler"}, calls)
+		assert.Len(t, chain.Middlewares(), 4)
+	})
+
+	t.Run("append does not modify original chain", func(t *testing.T) {
+		chain := NewChain().Use("first", middleware("first"))
+		appended := chain.Append(middleware("second"))
+		assert.Equal(t, []string{"first"}, chain.Names())
+		assert.Len(t, appended.Names(), 2)
+	})
+
+	t.Run("use does not modify original chain", func(t *testing.T) {
+		chain := NewChain().Use("first", middleware("first"))
+		used := chain.Use("second", middleware("second"))
+		assert.Equal(t, []string{"first"}, chain.Names())
+		assert.Equal(t, []string{"first", "second"}, used.Names())
+	})
+
+	t.Run("names", func(t *testing.T) {
+		chain := NewChain(health.New("/health", nil).Middleware()).
+			Use("custom", middleware("custom"))
+		assert.Equal(t, []string{"health", "custom"}, chain.Names())
+	})
+
+	t.Run("names of conditional middlewares", func(t *testing.T) {
+		var (
+			healthMiddleware = health.New("/health", nil).Middleware()
+			chain            = NewChain().
+						When(func(req Request) bool { return true }, healthMiddleware).
+						Use("paths", ForPaths(healthMiddleware, "/health")).
+						Append(middleware("custom"))
+		)
+		assert.Equal(t, []string{"health", "paths", "api_test"}, chain.Names())
+	})
+
+	t.Run("conditional middlewares", func(t *testing.T) {
+		for _, test := range []struct {
+			name   string
+			method string
+			path   string
+			calls  []string
+		}{
+			{
+				name:   "match all",
+				method: http.MethodPost,
+				path:   "/events/123",
+				calls:  []string{"paths", "methods", "when", "handler"},
+			},
+			{
+				name:   "unmatched method",
+				method: http.MethodGet,
+				path:   "/events/123",
+				calls:  []string{"paths", "when", "handler"},
+			},
+			{
+				name:   "unmatched path",
+				method: http.MethodPost,
+				path:   "/users",
+				calls:  []string{"methods", "handler"},
+			},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				calls = nil
+				var (
+					req = apitest.RequestBuilder{
+						Method: test.method,
+						Path:   test.path,
+					}.Build()
+					chain = NewChain().
+						Use("paths", ForPaths(middleware("paths"), "/events/*")).
+						Use("methods", ForMethods(middleware("methods"), http.MethodPost)).
+						When(func(req Request) bool {
+							return req.Path() == "/events/123"
+						}, middleware("when"))
+				)
+				chain.Then(handler)(context.Background(), req)
+				assert.Equal(t, test.calls, calls)
+			})
+		}
+	})
+}
@@ -0,0 +1,502 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/tinylib/msgp/msgp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encoder encodes response bodies into a media type.
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, body interface{}) error
+}
+
+// RegisterEncoder adds the encoder to the registry, replacing the one with the same media type.
+// Encoders registered earlier are preferred when the Accept header matches several of them.
+func RegisterEncoder(enc Encoder) {
+	encoders.Lock()
+	defer encoders.Unlock()
+	mediaType := mediaTypeOf(enc.ContentType())
+	for i, e := range encoders.list {
+		if mediaTypeOf(e.ContentType()) == mediaType {
+			encoders.list[i] = enc
+			return
+		}
+	}
+	encoders.list = append(encoders.list, enc)
+}
+
+// LookupEncoder returns the encoder registered for the media type.
+func LookupEncoder(mediaType string) (Encoder, bool) {
+	encoders.RLock()
+	defer encoders.RUnlock()
+	mediaType = mediaTypeOf(mediaType)
+	for _, enc := range encoders.list {
+		if mediaTypeOf(enc.ContentType()) == mediaType {
+			return enc, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate returns the encoder for the response selected by the Accept header.
+// The encoding forced by Response.WithEncoding takes precedence over the Accept header,
+// and error responses fall back to their default encoding instead of failing negotiation.
+func Negotiate(resp Response, accept string) (Encoder, bool) {
+	if encoding := resp.Encoding(); encoding != "" {
+		return LookupEncoder(encoding)
+	}
+	contentType := resp.Headers()[headerContentType]
+	if mediaTypeOf(contentType) != contentTypeProblemJSON {
+		if enc, ok := negotiate(accept); ok {
+			return enc, true
+		}
+	}
+	if resp.Status() >= 400 {
+		return LookupEncoder(contentType)
+	}
+	return nil, false
+}
+
+func negotiate(accept string) (Encoder, bool) {
+	if strings.TrimSpace(accept) == "" {
+		accept = "*/*"
+	}
+	ranges := parseAccept(accept)
+	preferJSON := acceptsAny(ranges) && !excluded(ranges, contentTypeJSON)
+	encoders.RLock()
+	defer encoders.RUnlock()
+	for _, r := range ranges {
+		if r.quality <= 0 {
+			continue
+		}
+		if preferJSON && r.quality < ranges[0].quality {
+			// browsers list their document types first and accept anything with a lower quality,
+			// e.g. "text/html,application/xml;q=0.9,*/*;q=0.8", so JSON is preferred to the rest
+			for _, enc := range encoders.list {
+				if mediaTypeOf(enc.ContentType()) == contentTypeJSON {
+					return enc, true
+				}
+			}
+		}
+		for _, enc := range encoders.list {
+			mediaType := mediaTypeOf(enc.ContentType())
+			if mediaType == contentTypeProblemJSON {
+				continue
+			}
+			if r.match(mediaType) && !excluded(ranges, mediaType) {
+				return enc, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Accepts reports whether the Accept header accepts the content type. An empty header accepts any.
+func Accepts(accept, contentType string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+	ranges := parseAccept(accept)
+	mediaType := mediaTypeOf(contentType)
+	if excluded(ranges, mediaType) {
+		return false
+	}
+	for _, r := range ranges {
+		if r.quality > 0 && r.match(mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+func (r acceptRange) match(mediaType string) bool {
+	if r.mediaType == "*/*" || r.mediaType == mediaType {
+		return true
+	}
+	if strings.HasSuffix(r.mediaType, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*"))
+	}
+	return false
+}
+
+func (r acceptRange) specificity() int {
+	switch {
+	case r.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(r.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// acceptsAny reports whether the ranges accept any media type with */*.
+func acceptsAny(ranges []acceptRange) bool {
+	for _, r := range ranges {
+		if r.mediaType == "*/*" && r.quality > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded reports whether the media type is explicitly refused with q=0.
+func excluded(ranges []acceptRange, mediaType string) bool {
+	for _, r := range ranges {
+		if r.quality <= 0 && r.mediaType == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAccept parses the Accept header sorted by quality and specificity.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		r := acceptRange{mediaType: mediaType, quality: 1}
+		if q, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				r.quality = v
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+func mediaTypeOf(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// jsonEncoder encodes bodies as JSON.
+type jsonEncoder struct {
+	contentType string
+}
+
+func (e *jsonEncoder) ContentType() string {
+	return e.contentType
+}
+
+func (e *jsonEncoder) Encode(w io.Writer, body interface{}) error {
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		return JSONEncodeError.Wrapf(err,
+			"Failed to encode json object: error=%v", err.Error())
+	}
+	return nil
+}
+
+// xmlEncoder encodes bodies as XML. Bodies that do not implement xml.Marshaler
+// are encoded in the same shape as their JSON representation, in a response element
+// whose arrays are lists of item elements.
+type xmlEncoder struct{}
+
+func (e *xmlEncoder) ContentType() string {
+	return "application/xml"
+}
+
+func (e *xmlEncoder) Encode(w io.Writer, body interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	var (
+		enc = xml.NewEncoder(w)
+		err error
+	)
+	if m, ok := body.(xml.Marshaler); ok {
+		err = enc.Encode(m)
+	} else {
+		var v interface{}
+		if v, err = toGeneric(body); err == nil {
+			if err = encodeXMLElement(enc, "response", v); err == nil {
+				err = enc.Flush()
+			}
+		}
+	}
+	if err != nil {
+		return EncodeError.Wrapf(err,
+			"Failed to encode xml object: error=%v", err.Error())
+	}
+	return nil
+}
+
+func encodeXMLElement(enc *xml.Encoder, name string, v interface{}) error {
+	if !isXMLName(name) {
+		return fmt.Errorf("invalid element name: %v", name)
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := encodeXMLElement(enc, key, v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := encodeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// isXMLName reports whether the name is usable as an element name, e.g. not "1st" or "first name".
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || unicode.IsLetter(c):
+		case i > 0 && (c == '-' || c == '.' || unicode.IsDigit(c)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// msgpackEncoder encodes bodies as MessagePack. Bodies that do not implement msgp.Marshaler
+// are encoded in the same shape as their JSON representation.
+type msgpackEncoder struct{}
+
+func (e *msgpackEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+func (e *msgpackEncoder) Encode(w io.Writer, body interface{}) error {
+	var (
+		b   []byte
+		err error
+	)
+	if m, ok := body.(msgp.Marshaler); ok {
+		b, err = m.MarshalMsg(nil)
+	} else {
+		var v interface{}
+		if v, err = toGeneric(body); err == nil {
+			b, err = msgp.AppendIntf(nil, v)
+		}
+	}
+	if err != nil {
+		return EncodeError.Wrapf(err,
+			"Failed to encode msgpack object: error=%v", err.Error())
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// protobufEncoder encodes bodies that implement proto.Message.
+type protobufEncoder struct{}
+
+func (e *protobufEncoder) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (e *protobufEncoder) Encode(w io.Writer, body interface{}) error {
+	m, ok := body.(proto.Message)
+	if !ok {
+		return EncodeError.New(fmt.Sprintf(
+			"Failed to encode protobuf object: type=%T is not proto.Message", body))
+	}
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return EncodeError.Wrapf(err,
+			"Failed to encode protobuf object: error=%v", err.Error())
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// textEncoder encodes strings, bytes, errors and messages as plain text.
+type textEncoder struct{}
+
+func (e *textEncoder) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (e *textEncoder) Encode(w io.Writer, body interface{}) error {
+	var err error
+	switch body := body.(type) {
+	case map[string]string:
+		message, ok := body["message"]
+		if !ok || len(body) != 1 {
+			return EncodeError.New(fmt.Sprintf(
+				"Failed to encode text object: type=%T without a single message is not supported", body))
+		}
+		_, err = io.WriteString(w, message)
+	case json.RawMessage:
+		_, err = w.Write(body)
+	case []byte:
+		_, err = w.Write(body)
+	case string:
+		_, err = io.WriteString(w, body)
+	case error:
+		_, err = io.WriteString(w, body.Error())
+	default:
+		return EncodeError.New(fmt.Sprintf(
+			"Failed to encode text object: type=%T is not supported", body))
+	}
+	return err
+}
+
+// csvEncoder encodes [][]string bodies, or lists of objects with a header row of their sorted keys.
+type csvEncoder struct{}
+
+func (e *csvEncoder) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (e *csvEncoder) Encode(w io.Writer, body interface{}) error {
+	records, err := csvRecords(body)
+	if err != nil {
+		return EncodeError.Wrapf(err,
+			"Failed to encode csv object: error=%v", err.Error())
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return EncodeError.Wrapf(err,
+			"Failed to encode csv object: error=%v", err.Error())
+	}
+	return nil
+}
+
+func csvRecords(body interface{}) ([][]string, error) {
+	if records, ok := body.([][]string); ok {
+		return records, nil
+	}
+	v, err := toGeneric(body)
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unsupported type: %T", body)
+	}
+	var header []string
+	if len(rows) > 0 {
+		first, ok := rows[0].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unsupported element type: %T", rows[0])
+		}
+		for key := range first {
+			header = append(header, key)
+		}
+		sort.Strings(header)
+	}
+	records := [][]string{header}
+	for _, row := range rows {
+		obj, ok := row.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unsupported element type: %T", row)
+		}
+		record := make([]string, len(header))
+		for i, key := range header {
+			if value, ok := obj[key]; ok && value != nil {
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// toGeneric converts the body to maps, slices and scalars through its JSON representation,
+// so that json tags are respected by the non-JSON encoders.
+func toGeneric(body interface{}) (interface{}, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fromJSONNumber(v), nil
+}
+
+func fromJSONNumber(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = fromJSONNumber(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = fromJSONNumber(value)
+		}
+	}
+	return v
+}
+
+var (
+	encoders = struct {
+		sync.RWMutex
+		list []Encoder
+	}{
+		list: []Encoder{
+			&jsonEncoder{contentType: contentTypeJSON},
+			&xmlEncoder{},
+			&msgpackEncoder{},
+			&protobufEncoder{},
+			&textEncoder{},
+			&csvEncoder{},
+			&jsonEncoder{contentType: contentTypeProblemJSON},
+		},
+	}
+)
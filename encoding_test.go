@@ -0,0 +1,225 @@
+package api_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tinylib/msgp/msgp"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	. "github.com/gotech-labs/api"
+)
+
+func TestNegotiate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		response    Response
+		accept      string
+		contentType string
+		ok          bool
+	}{
+		{
+			name:        "empty accept",
+			response:    OK("OK"),
+			accept:      "",
+			contentType: "application/json",
+			ok:          true,
+		},
+		{
+			name:        "any media type",
+			response:    OK("OK"),
+			accept:      "*/*",
+			contentType: "application/json",
+			ok:          true,
+		},
+		{
+			name:        "xml",
+			response:    OK("OK"),
+			accept:      "application/xml",
+			contentType: "application/xml",
+			ok:          true,
+		},
+		{
+			name:        "quality",
+			response:    OK("OK"),
+			accept:      "application/json;q=0.5, text/csv;q=0.8, text/plain;q=0.1",
+			contentType: "text/csv; charset=utf-8",
+			ok:          true,
+		},
+		{
+			name:        "wildcard subtype",
+			response:    OK("OK"),
+			accept:      "application/*, text/plain;q=0.5",
+			contentType: "application/json",
+			ok:          true,
+		},
+		{
+			name:        "excluded media type",
+			response:    OK("OK"),
+			accept:      "application/json;q=0, */*;q=0.1",
+			contentType: "application/xml",
+			ok:          true,
+		},
+		{
+			name:        "browser",
+			response:    OK("OK"),
+			accept:      "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			contentType: "application/json",
+			ok:          true,
+		},
+		{
+			name:        "preferred media type with any media type",
+			response:    OK("OK"),
+			accept:      "text/csv, */*;q=0.1",
+			contentType: "text/csv; charset=utf-8",
+			ok:          true,
+		},
+		{
+			name:     "not acceptable",
+			response: OK("OK"),
+			accept:   "image/png",
+			ok:       false,
+		},
+		{
+			name:        "not acceptable error response",
+			response:    BadRequest(fmt.Errorf("error")),
+			accept:      "image/png",
+			contentType: "application/json",
+			ok:          true,
+		},
+		{
+			name:        "forced encoding",
+			response:    OK("OK").WithEncoding("text/csv"),
+			accept:      "application/json",
+			contentType: "text/csv; charset=utf-8",
+			ok:          true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc, ok := Negotiate(test.response, test.accept)
+			if assert.Equal(t, test.ok, ok) && ok {
+				assert.Equal(t, test.contentType, enc.ContentType())
+			}
+		})
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	for _, test := range []struct {
+		accept   string
+		expected bool
+	}{
+		{accept: "", expected: true},
+		{accept: "application/json", expected: true},
+		{accept: "application/*", expected: true},
+		{accept: "text/csv, */*;q=0.1", expected: true},
+		{accept: "text/csv", expected: false},
+		{accept: "*/*, application/json;q=0", expected: false},
+	} {
+		t.Run(test.accept, func(t *testing.T) {
+			assert.Equal(t, test.expected, Accepts(test.accept, "application/json; charset=utf-8"))
+		})
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	type event struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	encode := func(mediaType string, body interface{}) (string, error) {
+		enc, ok := LookupEncoder(mediaType)
+		if !ok {
+			return "", fmt.Errorf("encoder not found: %v", mediaType)
+		}
+		buf := new(bytes.Buffer)
+		err := enc.Encode(buf, body)
+		return buf.String(), err
+	}
+
+	t.Run("json", func(t *testing.T) {
+		actual, err := encode("application/json", OK(&event{ID: 1, Name: "event"}).Body())
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `{"id": 1, "name": "event"}`, actual)
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		actual, err := encode("application/xml", &event{ID: 1, Name: "event"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<response><id>1</id><name>event</name></response>`, actual)
+		}
+		actual, err = encode("application/xml", []*event{{ID: 1, Name: "first"}, {ID: 2}})
+		if assert.NoError(t, err) {
+			assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+				`<response><item><id>1</id><name>first</name></item><item><id>2</id><name></name></item></response>`, actual)
+		}
+		_, err = encode("application/xml", map[string]int{"1st": 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("msgpack", func(t *testing.T) {
+		actual, err := encode("application/msgpack", &event{ID: 1, Name: "event"})
+		if assert.NoError(t, err) {
+			decoded, _, err := msgp.ReadIntfBytes([]byte(actual))
+			if assert.NoError(t, err) {
+				assert.Equal(t, map[string]interface{}{"id": int64(1), "name": "event"}, decoded)
+			}
+		}
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		actual, err := encode("application/x-protobuf", wrapperspb.String("event"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "\n\x05event", actual)
+		}
+		_, err = encode("application/x-protobuf", &event{ID: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		actual, err := encode("text/plain", OK("hello").Body())
+		if assert.NoError(t, err) {
+			assert.Equal(t, "hello", actual)
+		}
+		_, err = encode("text/plain", &event{ID: 1})
+		assert.Error(t, err)
+		_, err = encode("text/plain", map[string]string{"message": "hello", "detail": "world"})
+		assert.Error(t, err)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		actual, err := encode("text/csv", []*event{{ID: 1, Name: "first"}, {ID: 2, Name: "second, third"}})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "id,name\n1,first\n2,\"second, third\"\n", actual)
+		}
+		actual, err = encode("text/csv", [][]string{{"id"}, {"1"}})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "id\n1\n", actual)
+		}
+		_, err = encode("text/csv", &event{ID: 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder(&yamlEncoder{})
+	enc, ok := Negotiate(OK("OK"), "application/yaml")
+	if assert.True(t, ok) {
+		assert.Equal(t, "application/yaml", enc.ContentType())
+	}
+}
+
+type yamlEncoder struct{}
+
+func (e *yamlEncoder) ContentType() string {
+	return "application/yaml"
+}
+
+func (e *yamlEncoder) Encode(w io.Writer, body interface{}) error {
+	_, err := fmt.Fprintf(w, "%v", body)
+	return err
+}
@@ -1,8 +1,66 @@
 package api
 
-import "github.com/gotech-labs/core/errors"
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gotech-labs/core/errors"
+	"golang.org/x/xerrors"
+)
 
 var (
-	BindingError    = errors.TypedError("binding_error")
-	JSONEncodeError = errors.TypedError("json_encode_error")
+	BindingError      = errors.TypedError("binding_error")
+	JSONEncodeError   = errors.TypedError("json_encode_error")
+	RoutingError      = errors.TypedError("routing_error")
+	EncodeError       = errors.TypedError("encode_error")
+	WebSocketError    = errors.TypedError("websocket_error")
+	BodyTooLargeError = errors.TypedError("body_too_large_error")
+	FormError         = errors.TypedError("form_error")
+	TimeoutError      = errors.TypedError("timeout_error")
 )
+
+// Error returns the error response with the status code registered for the error.
+// Unregistered errors are responded as internal server error.
+func Error(err error) Response {
+	return newResponse(ErrorStatus(err), err)
+}
+
+// ErrorStatus walks the wrapped chain of the error and returns the status code registered
+// for the first TypedError found, or http.StatusInternalServerError.
+func ErrorStatus(err error) int {
+	for e := err; e != nil; e = xerrors.Unwrap(e) {
+		switch typed := e.(type) {
+		case *Problem:
+			if typed.Status != 0 {
+				return typed.Status
+			}
+		case interface{ Type() errors.TypedError }:
+			if status, ok := errorStatuses.Load(typed.Type()); ok {
+				return status.(int)
+			}
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// RegisterErrorStatus maps the TypedError to the http status code.
+func RegisterErrorStatus(typed errors.TypedError, status int) {
+	errorStatuses.Store(typed, status)
+}
+
+var (
+	errorStatuses sync.Map
+)
+
+func init() {
+	RegisterErrorStatus(BindingError, http.StatusBadRequest)
+	RegisterErrorStatus(JSONEncodeError, http.StatusInternalServerError)
+	RegisterErrorStatus(RoutingError, http.StatusNotFound)
+	RegisterErrorStatus(EncodeError, http.StatusInternalServerError)
+	RegisterErrorStatus(WebSocketError, http.StatusBadRequest)
+	RegisterErrorStatus(BodyTooLargeError, http.StatusRequestEntityTooLarge)
+	RegisterErrorStatus(FormError, http.StatusBadRequest)
+	RegisterErrorStatus(TimeoutError, http.StatusGatewayTimeout)
+	RegisterErrorStatus(errors.ValidationError, http.StatusBadRequest)
+	RegisterErrorStatus(errors.UnexpectedError, http.StatusInternalServerError)
+}
@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+
+	. "github.com/gotech-labs/api"
+	"github.com/gotech-labs/core/errors"
+)
+
+func TestError(t *testing.T) {
+	var (
+		notFoundError = errors.TypedError("event_not_found_error")
+		conflictError = errors.TypedError("event_conflict_error")
+	)
+	RegisterErrorStatus(notFoundError, http.StatusNotFound)
+	RegisterErrorStatus(conflictError, http.StatusConflict)
+
+	for _, test := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{
+			name:   "binding error",
+			err:    BindingError.New("binding error"),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "validation error",
+			err:    errors.ValidationError.New("validation error"),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "registered error",
+			err:    notFoundError.New("event not found"),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "wrapped registered error",
+			err:    xerrors.Errorf("failed to create event: %w", conflictError.New("duplicated")),
+			status: http.StatusConflict,
+		},
+		{
+			name:   "outermost registered error",
+			err:    notFoundError.Wrap(conflictError.New("duplicated")),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "problem",
+			err:    NewProblem(http.StatusGone, fmt.Errorf("event deleted")),
+			status: http.StatusGone,
+		},
+		{
+			name:   "unregistered error",
+			err:    fmt.Errorf("unknown error"),
+			status: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Error(test.err)
+			assert.Equal(t, test.status, actual.Status())
+			assert.Equal(t, test.status, ErrorStatus(test.err))
+		})
+	}
+}
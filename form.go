@@ -0,0 +1,81 @@
+package api
+
+import (
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+)
+
+// FormFile is a file uploaded in a multipart/form-data request.
+// Small files are kept in memory and large ones are spooled to a temporary file,
+// which is removed when the request is finished.
+type FormFile struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Size        int64
+	Header      textproto.MIMEHeader
+	header      *multipart.FileHeader
+}
+
+// NewFormFile returns the FormFile of the multipart file header.
+func NewFormFile(field string, fh *multipart.FileHeader) *FormFile {
+	return &FormFile{
+		Field:       field,
+		Filename:    fh.Filename,
+		ContentType: fh.Header.Get(headerContentType),
+		Size:        fh.Size,
+		Header:      fh.Header,
+		header:      fh,
+	}
+}
+
+// Open opens the content of the file.
+func (f *FormFile) Open() (io.ReadCloser, error) {
+	file, err := f.header.Open()
+	if err != nil {
+		return nil, FormError.Wrapf(err,
+			"Failed to open form file: field=%v, filename=%v, error=%v", f.Field, f.Filename, err.Error())
+	}
+	return file, nil
+}
+
+// ReadAll reads the content of the file into memory.
+func (f *FormFile) ReadAll() ([]byte, error) {
+	file, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return nil, FormError.Wrapf(err,
+			"Failed to read form file: field=%v, filename=%v, error=%v", f.Field, f.Filename, err.Error())
+	}
+	return b, nil
+}
+
+// SaveTo streams the content of the file to the path.
+func (f *FormFile) SaveTo(path string) error {
+	file, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	out, err := os.Create(path)
+	if err != nil {
+		return FormError.Wrapf(err,
+			"Failed to save form file: path=%v, error=%v", path, err.Error())
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		return FormError.Wrapf(err,
+			"Failed to save form file: path=%v, error=%v", path, err.Error())
+	}
+	if err := out.Close(); err != nil {
+		return FormError.Wrapf(err,
+			"Failed to save form file: path=%v, error=%v", path, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gotech-labs/api"
+)
+
+// BindOptions configures how Bind decodes JSON bodies.
+type BindOptions struct {
+	// DisallowUnknownFields rejects object keys that do not match any field of the destination.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers into interface{} values as json.Number instead of float64.
+	UseNumber bool
+	// DisallowTrailingData rejects any data after the first JSON value.
+	DisallowTrailingData bool
+	// MaxDepth limits the nesting of objects and arrays. Zero means no limit.
+	MaxDepth int
+	// MaxBytes limits the size of the JSON body, below the request body limit. Zero means no limit.
+	MaxBytes int64
+}
+
+// DefaultBindOptions is the options of Bind for the requests created by NewRequest.
+var DefaultBindOptions = BindOptions{}
+
+// WithBindOptions sets the options of Bind.
+func WithBindOptions(opts BindOptions) RequestOption {
+	return func(r *request) {
+		r.bindOptions = opts
+	}
+}
+
+func decodeJSON(body []byte, obj interface{}, opts BindOptions) error {
+	if opts.MaxBytes > 0 && int64(len(body)) > opts.MaxBytes {
+		return api.BodyTooLargeError.New(fmt.Sprintf(
+			"JSON body too large: size=%v, limit=%v", len(body), opts.MaxBytes))
+	}
+	if opts.MaxDepth > 0 {
+		if err := checkDepth(body, opts.MaxDepth); err != nil {
+			return err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if opts.UseNumber {
+		dec.UseNumber()
+	}
+	err := dec.Decode(obj)
+	if err != nil {
+		if ute, ok := err.(*json.UnmarshalTypeError); ok {
+			return api.BindingError.Wrapf(err,
+				"Unmarshal type error: expected=%v, got=%v, field=%v, offset=%v", ute.Type, ute.Value, ute.Field, ute.Offset)
+		} else if se, ok := err.(*json.SyntaxError); ok {
+			return api.BindingError.Wrapf(err,
+				"Syntax error: offset=%v, error=%v", se.Offset, se.Error())
+		} else if strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+			return api.BindingError.Wrapf(err,
+				"Unknown field: field=%v", strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`))
+		}
+		return api.BindingError.Wrapf(err,
+			"Failed to binding object: error=%v", err.Error())
+	}
+	if opts.DisallowTrailingData {
+		if _, err := dec.Token(); err != io.EOF {
+			return api.BindingError.New(fmt.Sprintf(
+				"Trailing data after JSON value: offset=%v", dec.InputOffset()))
+		}
+	}
+	return nil
+}
+
+// checkDepth scans the JSON body and fails when objects and arrays are nested deeper than the limit.
+func checkDepth(body []byte, limit int) error {
+	var (
+		depth   int
+		inStr   bool
+		escaped bool
+	)
+	for i, c := range body {
+		if inStr {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inStr = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inStr = true
+		case '{', '[':
+			depth++
+			if depth > limit {
+				return api.BindingError.New(fmt.Sprintf(
+					"JSON nesting too deep: limit=%v, offset=%v", limit, i))
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+const (
+	unknownFieldPrefix = "json: unknown field "
+)
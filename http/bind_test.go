@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	. "github.com/gotech-labs/api/http"
+	apitest "github.com/gotech-labs/api/testing"
+)
+
+func TestBindOptions(t *testing.T) {
+	type Input struct {
+		ID    int64       `json:"id"`
+		Extra interface{} `json:"extra"`
+	}
+	for _, tc := range []struct {
+		name     string
+		options  BindOptions
+		body     string
+		expected string
+		status   int
+	}{
+		{
+			name: "unknown fields are ignored by default",
+			body: `{"id": 1, "unknown": true}`,
+		},
+		{
+			name:     "disallow unknown fields",
+			options:  BindOptions{DisallowUnknownFields: true},
+			body:     `{"id": 1, "unknown": true}`,
+			expected: "Unknown field: field=unknown",
+			status:   http.StatusBadRequest,
+		},
+		{
+			name: "trailing data is ignored by default",
+			body: `{"id": 1} {"id": 2}`,
+		},
+		{
+			name:     "disallow trailing data",
+			options:  BindOptions{DisallowTrailingData: true},
+			body:     `{"id": 1} {"id": 2}`,
+			expected: "Trailing data after JSON value: offset=11",
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "disallow trailing garbage",
+			options:  BindOptions{DisallowTrailingData: true},
+			body:     `{"id": 1}}`,
+			expected: "Trailing data after JSON value: offset=9",
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:    "allow trailing whitespace",
+			options: BindOptions{DisallowTrailingData: true},
+			body:    "{\"id\": 1}\n",
+		},
+		{
+			name:    "max depth",
+			options: BindOptions{MaxDepth: 2},
+			body:    `{"id": 1, "extra": {"key": "[{}]"}}`,
+		},
+		{
+			name:     "max depth exceeded",
+			options:  BindOptions{MaxDepth: 2},
+			body:     `{"id": 1, "extra": {"key": [1]}}`,
+			expected: "JSON nesting too deep: limit=2, offset=27",
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "max bytes exceeded",
+			options:  BindOptions{MaxBytes: 8},
+			body:     `{"id": 1}`,
+			expected: "JSON body too large: size=9, limit=8",
+			status:   http.StatusRequestEntityTooLarge,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				input Input
+				req   = NewRequest(apitest.RequestBuilder{
+					Method: http.MethodPost,
+					Path:   "/events",
+					Body:   []byte(tc.body),
+				}.Build(), WithBindOptions(tc.options))
+			)
+			err := req.Bind(&input)
+			if tc.expected == "" {
+				if assert.NoError(t, err) {
+					assert.Equal(t, int64(1), input.ID)
+				}
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.expected, err.Error())
+				assert.Equal(t, tc.status, api.ErrorStatus(err))
+			}
+		})
+	}
+
+	t.Run("use number", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method: http.MethodPost,
+				Path:   "/events",
+				Body:   []byte(`{"id": 1, "extra": 9007199254740993}`),
+			}.Build(), WithBindOptions(BindOptions{UseNumber: true}))
+		)
+		if assert.NoError(t, req.Bind(&input)) {
+			assert.Equal(t, json.Number("9007199254740993"), input.Extra)
+		}
+	})
+
+	t.Run("global and per route options", func(t *testing.T) {
+		DefaultBindOptions = BindOptions{DisallowUnknownFields: true}
+		defer func() { DefaultBindOptions = BindOptions{} }()
+
+		var (
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				var input Input
+				if err := req.Bind(&input); err != nil {
+					return api.Error(err)
+				}
+				return api.NoContent()
+			}
+			router = NewRouter()
+		)
+		router.POST("/strict", handler)
+		router.POST("/lenient", handler).WithRequestOptions(WithBindOptions(BindOptions{}))
+
+		for path, status := range map[string]int{
+			"/strict":  http.StatusBadRequest,
+			"/lenient": http.StatusNoContent,
+		} {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"id": 1, "unknown": true}`)))
+			assert.Equal(t, status, rec.Code, path)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"sort"
+
+	"github.com/gotech-labs/api"
+	"golang.org/x/xerrors"
+)
+
+// DefaultMaxMultipartSize is the maximum size of multipart form bodies, which are streamed
+// instead of buffered, so they are not limited by DefaultMaxBodySize.
+var DefaultMaxMultipartSize int64 = 32 << 20
+
+// DefaultMaxMultipartMemory is the maximum size of multipart forms kept in memory.
+// Larger files are spooled to temporary files.
+var DefaultMaxMultipartMemory int64 = 8 << 20
+
+// DefaultMaxFileSize is the maximum size of each uploaded file. Zero means no limit other than the body size.
+var DefaultMaxFileSize int64 = 0
+
+// WithMaxMultipartSize sets the maximum size of multipart form bodies.
+func WithMaxMultipartSize(size int64) RequestOption {
+	return func(r *request) {
+		r.maxMultipartSize = size
+	}
+}
+
+// WithMaxMultipartMemory sets the maximum size of multipart forms kept in memory.
+func WithMaxMultipartMemory(size int64) RequestOption {
+	return func(r *request) {
+		r.maxMultipartMemory = size
+	}
+}
+
+// WithMaxFileSize sets the maximum size of each uploaded file.
+func WithMaxFileSize(size int64) RequestOption {
+	return func(r *request) {
+		r.maxFileSize = size
+	}
+}
+
+// FormValue returns the first value of the url-encoded or multipart form field.
+// Use MultipartFiles to get the error when the form can not be parsed.
+func (r *request) FormValue(key string) string {
+	if r.parseForm() != nil {
+		return ""
+	}
+	return r.form.Get(key)
+}
+
+// FormValues returns all values of the url-encoded or multipart form field.
+func (r *request) FormValues(key string) []string {
+	if r.parseForm() != nil {
+		return nil
+	}
+	return r.form[key]
+}
+
+// FormFile returns the first file uploaded with the multipart form field, or nil.
+func (r *request) FormFile(key string) *api.FormFile {
+	if r.parseForm() != nil || r.multipartForm == nil {
+		return nil
+	}
+	if files := r.multipartForm.File[key]; len(files) > 0 {
+		return api.NewFormFile(key, files[0])
+	}
+	return nil
+}
+
+// MultipartFiles calls fn for each uploaded file in the order of field names, and stops at the first error.
+// The body of multipart requests is streamed while parsing, so it is not available from Body unless
+// it has been read before.
+func (r *request) MultipartFiles(fn func(file *api.FormFile) error) error {
+	if err := r.parseForm(); err != nil {
+		return err
+	}
+	if r.multipartForm == nil {
+		return nil
+	}
+	fields := make([]string, 0, len(r.multipartForm.File))
+	for field := range r.multipartForm.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		for _, fh := range r.multipartForm.File[field] {
+			if err := fn(api.NewFormFile(field, fh)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *request) parseForm() error {
+	r.formOnce.Do(func() {
+		r.form, r.multipartForm, r.formErr = r.readForm()
+	})
+	return r.formErr
+}
+
+func (r *request) readForm() (url.Values, *multipart.Form, error) {
+	mediaType, params, _ := mime.ParseMediaType(r.Request.Header.Get(headerContentType))
+	switch mediaType {
+	case contentTypeForm:
+		body, err := r.ReadBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, nil, api.FormError.Wrapf(err,
+				"Failed to parse form: error=%v", err.Error())
+		}
+		return values, nil, nil
+	case contentTypeMultipart:
+		boundary := params["boundary"]
+		if boundary == "" {
+			return nil, nil, api.FormError.New("Failed to parse multipart form: missing boundary")
+		}
+		body := r.multipartBody()
+		form, err := multipart.NewReader(body, boundary).ReadForm(r.maxMultipartMemory)
+		if body, ok := body.(*limitedBody); ok && body.exceeded {
+			return nil, nil, api.BodyTooLargeError.New(fmt.Sprintf(
+				"Request body too large: limit=%v", r.maxMultipartSize))
+		}
+		if err != nil {
+			if xerrors.Is(err, multipart.ErrMessageTooLarge) {
+				return nil, nil, api.BodyTooLargeError.Wrapf(err,
+					"Multipart form too large: error=%v", err.Error())
+			}
+			return nil, nil, api.FormError.Wrapf(err,
+				"Failed to parse multipart form: error=%v", err.Error())
+		}
+		if err := r.checkFileSize(form); err != nil {
+			_ = form.RemoveAll()
+			return nil, nil, err
+		}
+		return url.Values(form.Value), form, nil
+	}
+	return url.Values{}, nil, nil
+}
+
+// multipartBody returns the buffered body when it has been read, or streams the body with the multipart size limit.
+func (r *request) multipartBody() io.Reader {
+	streamed := false
+	r.bodyOnce.Do(func() {
+		streamed = true
+	})
+	if !streamed {
+		return bytes.NewReader(r.body)
+	}
+	if r.Request.Body == nil {
+		return bytes.NewReader(nil)
+	}
+	if r.maxMultipartSize <= 0 {
+		return r.Request.Body
+	}
+	return &limitedBody{reader: r.Request.Body, remaining: r.maxMultipartSize}
+}
+
+func (r *request) checkFileSize(form *multipart.Form) error {
+	if r.maxFileSize <= 0 {
+		return nil
+	}
+	for field, files := range form.File {
+		for _, fh := range files {
+			if fh.Size > r.maxFileSize {
+				return api.BodyTooLargeError.New(fmt.Sprintf(
+					"Form file too large: field=%v, filename=%v, size=%v, limit=%v", field, fh.Filename, fh.Size, r.maxFileSize))
+			}
+		}
+	}
+	return nil
+}
+
+// isForm reports whether the request body is an url-encoded or multipart form.
+func (r *request) isForm() bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Request.Header.Get(headerContentType))
+	return mediaType == contentTypeForm || mediaType == contentTypeMultipart
+}
+
+// close removes the temporary files of the multipart form.
+func (r *request) close() {
+	if r.multipartForm != nil {
+		_ = r.multipartForm.RemoveAll()
+	}
+}
+
+// limitedBody reads up to the limit and fails afterwards.
+type limitedBody struct {
+	reader    io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		b.exceeded = true
+		return 0, api.BodyTooLargeError.New("Request body too large")
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.reader.Read(p)
+	if int64(n) > b.remaining {
+		b.exceeded = true
+		return int(b.remaining), api.BodyTooLargeError.New("Request body too large")
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+const (
+	contentTypeForm      = "application/x-www-form-urlencoded"
+	contentTypeMultipart = "multipart/form-data"
+)
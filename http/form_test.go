@@ -0,0 +1,290 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	. "github.com/gotech-labs/api/http"
+	apitest "github.com/gotech-labs/api/testing"
+)
+
+func TestFormValues(t *testing.T) {
+	t.Run("url-encoded form", func(t *testing.T) {
+		req := NewRequest(apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			FormValues: map[string][]string{
+				"name": {"Michael Jordan"},
+				"tags": {"nba", "bulls"},
+			},
+		}.Build())
+
+		assert.Equal(t, "Michael Jordan", req.FormValue("name"))
+		assert.Equal(t, []string{"nba", "bulls"}, req.FormValues("tags"))
+		assert.Equal(t, "", req.FormValue("unknown"))
+		assert.Nil(t, req.FormFile("file"))
+		// the url-encoded body is still readable
+		assert.NotEmpty(t, req.Body())
+	})
+
+	t.Run("multipart form", func(t *testing.T) {
+		req := NewRequest(apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			FormValues: map[string][]string{
+				"name": {"Michael Jordan"},
+			},
+			Files: []apitest.File{
+				{Field: "photos", Filename: "a.png", ContentType: "image/png", Content: []byte("png a")},
+				{Field: "photos", Filename: "b.png", ContentType: "image/png", Content: []byte("png b")},
+				{Field: "document", Filename: "c.txt", Content: []byte("text")},
+			},
+		}.Build())
+
+		assert.Equal(t, "Michael Jordan", req.FormValue("name"))
+		file := req.FormFile("document")
+		if assert.NotNil(t, file) {
+			assert.Equal(t, "c.txt", file.Filename)
+			assert.Equal(t, "application/octet-stream", file.ContentType)
+			assert.Equal(t, int64(4), file.Size)
+			content, err := file.ReadAll()
+			if assert.NoError(t, err) {
+				assert.Equal(t, "text", string(content))
+			}
+		}
+
+		var filenames []string
+		err := req.MultipartFiles(func(file *api.FormFile) error {
+			filenames = append(filenames, file.Field+"/"+file.Filename)
+			return nil
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"document/c.txt", "photos/a.png", "photos/b.png"}, filenames)
+		}
+	})
+
+	t.Run("save file to disk", func(t *testing.T) {
+		var (
+			path = filepath.Join(t.TempDir(), "upload.png")
+			req  = NewRequest(apitest.RequestBuilder{
+				Method: http.MethodPost,
+				Path:   "/events",
+				Files: []apitest.File{
+					{Field: "photo", Filename: "a.png", Content: []byte("png a")},
+				},
+			}.Build(), WithMaxMultipartMemory(1))
+		)
+		if assert.NoError(t, req.FormFile("photo").SaveTo(path)) {
+			content, _ := os.ReadFile(path)
+			assert.Equal(t, "png a", string(content))
+		}
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		req := NewRequest(apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			Files: []apitest.File{
+				{Field: "photo", Filename: "a.png", Content: bytes.Repeat([]byte("a"), 100)},
+			},
+		}.Build(), WithMaxFileSize(10))
+
+		err := req.MultipartFiles(func(file *api.FormFile) error { return nil })
+		if assert.Error(t, err) {
+			assert.Equal(t, "Form file too large: field=photo, filename=a.png, size=100, limit=10", err.Error())
+			assert.Equal(t, http.StatusRequestEntityTooLarge, api.ErrorStatus(err))
+		}
+		assert.Nil(t, req.FormFile("photo"))
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		req := NewRequest(apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			Files: []apitest.File{
+				{Field: "photo", Filename: "a.png", Content: bytes.Repeat([]byte("a"), 100)},
+			},
+		}.Build(), WithMaxBodySize(50), WithMaxMultipartSize(50))
+
+		err := req.MultipartFiles(func(file *api.FormFile) error { return nil })
+		if assert.Error(t, err) {
+			assert.Equal(t, "Request body too large: limit=50", err.Error())
+		}
+	})
+
+	t.Run("file larger than body size", func(t *testing.T) {
+		req := NewRequest(apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			Files: []apitest.File{
+				{Field: "photo", Filename: "a.png", Content: bytes.Repeat([]byte("a"), 100)},
+			},
+		}.Build(), WithMaxBodySize(50))
+
+		if file := req.FormFile("photo"); assert.NotNil(t, file) {
+			assert.Equal(t, int64(100), file.Size)
+		}
+	})
+
+	t.Run("invalid multipart form", func(t *testing.T) {
+		req := NewRequest(apitest.RequestBuilder{
+			Method:  http.MethodPost,
+			Path:    "/events",
+			Body:    []byte("invalid"),
+			Headers: map[string][]string{"Content-Type": {"multipart/form-data"}},
+		}.Build())
+
+		err := req.MultipartFiles(func(file *api.FormFile) error { return nil })
+		if assert.Error(t, err) {
+			assert.Equal(t, "Failed to parse multipart form: missing boundary", err.Error())
+			assert.Equal(t, http.StatusBadRequest, api.ErrorStatus(err))
+		}
+	})
+}
+
+func TestBindForm(t *testing.T) {
+	type Input struct {
+		ID     int             `path:"id"`
+		Name   string          `form:"name" validate:"required"`
+		Tags   []string        `form:"tags"`
+		Age    int             `form:"age"`
+		Photo  *api.FormFile   `form:"photo" validate:"required"`
+		Photos []*api.FormFile `form:"photos"`
+	}
+
+	t.Run("success binding", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method:     http.MethodPost,
+				Path:       "/events/1",
+				PathParams: map[string]string{"id": "1"},
+				FormValues: map[string][]string{
+					"name": {"Michael Jordan"},
+					"tags": {"nba", "bulls"},
+					"age":  {"59"},
+				},
+				Files: []apitest.File{
+					{Field: "photo", Filename: "a.png", Content: []byte("png a")},
+					{Field: "photos", Filename: "b.png", Content: []byte("png b")},
+					{Field: "photos", Filename: "c.png", Content: []byte("png c")},
+				},
+			}.Build())
+		)
+		if assert.NoError(t, req.Bind(&input)) {
+			assert.Equal(t, 1, input.ID)
+			assert.Equal(t, "Michael Jordan", input.Name)
+			assert.Equal(t, []string{"nba", "bulls"}, input.Tags)
+			assert.Equal(t, 59, input.Age)
+			if assert.NotNil(t, input.Photo) {
+				assert.Equal(t, "a.png", input.Photo.Filename)
+			}
+			assert.Len(t, input.Photos, 2)
+		}
+	})
+
+	t.Run("binding error", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method: http.MethodPost,
+				Path:   "/events/1",
+				FormValues: map[string][]string{
+					"name": {"Michael Jordan"},
+					"age":  {"unknown"},
+				},
+			}.Build())
+		)
+		err := req.Bind(&input)
+		if assert.Error(t, err) {
+			assert.Equal(t, `Failed to binding request: field=age, source=form, reason=invalid int "unknown"`, err.Error())
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method:     http.MethodPost,
+				Path:       "/events/1",
+				FormValues: map[string][]string{"name": {"Michael Jordan"}},
+			}.Build())
+		)
+		err := req.Bind(&input)
+		if assert.Error(t, err) {
+			assert.Equal(t, "Failed to validate request: field=photo, source=form, reason=required", err.Error())
+		}
+	})
+}
+
+func TestMultipartTemporaryFiles(t *testing.T) {
+	var (
+		file    *api.FormFile
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			file = req.FormFile("photo")
+			return api.NoContent()
+		}
+		req = apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			Files: []apitest.File{
+				{Field: "photo", Filename: "a.png", Content: bytes.Repeat([]byte("a"), 1024)},
+			},
+		}.Build()
+		rec = httptest.NewRecorder()
+	)
+	defaultMemory := DefaultMaxMultipartMemory
+	DefaultMaxMultipartMemory = 1
+	defer func() { DefaultMaxMultipartMemory = defaultMemory }()
+
+	NewHandler(handler).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	if assert.NotNil(t, file) {
+		_, err := file.Open()
+		assert.Error(t, err)
+	}
+}
+
+func TestMultipartSpooledFile(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		content = bytes.Repeat([]byte("a"), int(DefaultMaxBodySize)+1)
+		spooled []os.DirEntry
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			file := req.FormFile("photo")
+			if file == nil {
+				return api.BadRequest(fmt.Errorf("no file"))
+			}
+			spooled, _ = os.ReadDir(dir)
+			b, err := file.ReadAll()
+			if err != nil || !bytes.Equal(content, b) {
+				return api.BadRequest(fmt.Errorf("unexpected content"))
+			}
+			return api.NoContent()
+		}
+		req = apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			Files: []apitest.File{
+				{Field: "photo", Filename: "a.png", Content: content},
+			},
+		}.Build()
+		rec = httptest.NewRecorder()
+	)
+	t.Setenv("TMPDIR", dir)
+
+	NewHandler(handler).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, spooled, 1)
+	remaining, _ := os.ReadDir(dir)
+	assert.Empty(t, remaining)
+}
@@ -0,0 +1,199 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gotech-labs/api"
+)
+
+// NewHandler returns http.Handler that serves the api handler function wrapped by middlewares.
+// The first middleware is the outermost one.
+func NewHandler(h api.HandlerFunc, middlewares ...api.MiddlewareFunc) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return &handler{
+		handlerFunc: h,
+	}
+}
+
+type handler struct {
+	handlerFunc    api.HandlerFunc
+	requestOptions []RequestOption
+	// options serves OPTIONS requests to the route with its middlewares, see Router.ServeHTTP
+	options api.HandlerFunc
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := NewRequest(r, h.requestOptions...).(*request)
+	defer req.close()
+	resp := h.handlerFunc(r.Context(), req)
+	writeResponse(w, r, resp)
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, resp api.Response) {
+	if ur, ok := resp.(api.UpgradeResponse); ok {
+		serveWebSocket(w, r, ur)
+		return
+	}
+	if sr, ok := resp.(api.StreamResponse); ok {
+		writeStream(w, r, sr)
+		return
+	}
+	var (
+		body        []byte
+		contentType string
+	)
+	if bodyAllowed(resp.Status()) && resp.Body() != nil {
+		resp, body, contentType = encodeResponse(resp, r.Header.Get(headerAccept))
+	}
+	header := w.Header()
+	for key, values := range resp.HeaderValues() {
+		header[key] = append([]string(nil), values...)
+	}
+	status := resp.Status()
+	if !bodyAllowed(status) {
+		header.Del(headerContentType)
+		header.Del(headerContentLength)
+		w.WriteHeader(status)
+		return
+	}
+	if len(body) == 0 {
+		header.Del(headerContentType)
+	} else {
+		header.Set(headerContentType, contentType)
+	}
+	header.Set(headerContentLength, strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead || len(body) == 0 {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// writeStream writes the stream response without buffering the body, then closes its content.
+// Seekable content of 200 responses is served by http.ServeContent to support range requests.
+func writeStream(w http.ResponseWriter, r *http.Request, resp api.StreamResponse) {
+	defer resp.Close()
+	header := w.Header()
+	for key, values := range resp.HeaderValues() {
+		header[key] = append([]string(nil), values...)
+	}
+	if content, modtime := resp.Seekable(); content != nil && resp.Status() == http.StatusOK {
+		http.ServeContent(w, r, "", modtime, content)
+		return
+	}
+	w.WriteHeader(resp.Status())
+	if r.Method == http.MethodHead || !bodyAllowed(resp.Status()) {
+		return
+	}
+	fw := &flushWriter{w: w}
+	// send the headers before the first chunk, e.g. for event streams
+	fw.flush()
+	if err := resp.Stream(fw); err != nil {
+		// the status has already been sent, so abort the connection to signal the truncated body
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// flushWriter flushes every write to the client.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	fw.flush()
+	return n, err
+}
+
+func (fw *flushWriter) flush() {
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// encodeResponse encodes the body with the encoder negotiated by the Accept header.
+// The body is encoded in the default encoding of the response when the negotiated encoder can not encode it,
+// e.g. CSV of objects, as long as the Accept header accepts it. The response is replaced with 406
+// when no encoder is acceptable, or with 500 when encoding fails.
+func encodeResponse(resp api.Response, accept string) (api.Response, []byte, string) {
+	enc, ok := api.Negotiate(resp, accept)
+	if !ok {
+		return encodeError(resp, notAcceptable(accept))
+	}
+	buf := new(bytes.Buffer)
+	err := enc.Encode(buf, resp.Body())
+	if fallback := defaultEncoder(resp); err != nil && fallback.ContentType() != enc.ContentType() {
+		// forced encodings and errors are not negotiated, so their fallback is not either
+		if resp.Encoding() == "" && resp.Status() < 400 && !api.Accepts(accept, fallback.ContentType()) {
+			return encodeError(resp, notAcceptable(accept))
+		}
+		enc = fallback
+		buf.Reset()
+		err = enc.Encode(buf, resp.Body())
+	}
+	if err != nil {
+		return encodeError(resp, api.InternalServerError(err))
+	}
+	return resp, buf.Bytes(), enc.ContentType()
+}
+
+func notAcceptable(accept string) api.Response {
+	return api.NotAcceptable(api.EncodeError.New(fmt.Sprintf(
+		"Not acceptable: accept=%v", accept)))
+}
+
+// encodeError encodes the error response that replaces the response in its default encoding.
+// The headers of the replaced response but the content type are kept,
+// e.g. CORS, request id and rate limit headers set by middlewares.
+func encodeError(resp, errResp api.Response) (api.Response, []byte, string) {
+	for key, values := range resp.HeaderValues() {
+		if http.CanonicalHeaderKey(key) == headerContentType {
+			continue
+		}
+		errResp = errResp.DelHeader(key)
+		for _, value := range values {
+			errResp = errResp.AddHeader(key, value)
+		}
+	}
+	enc, _ := api.Negotiate(errResp, "")
+	buf := new(bytes.Buffer)
+	if err := enc.Encode(buf, errResp.Body()); err != nil {
+		return errResp, nil, ""
+	}
+	return errResp, buf.Bytes(), enc.ContentType()
+}
+
+// defaultEncoder returns the encoder of the default content type of the response, or the JSON encoder.
+func defaultEncoder(resp api.Response) api.Encoder {
+	if enc, ok := api.LookupEncoder(resp.Headers()[headerContentType]); ok {
+		return enc
+	}
+	enc, _ := api.LookupEncoder(contentTypeJSON)
+	return enc
+}
+
+// bodyAllowed reports whether the status permits a response body (RFC 7230 section 3.3).
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status < 200:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+const (
+	headerAccept        = "Accept"
+	headerContentType   = "Content-Type"
+	headerContentLength = "Content-Length"
+
+	contentTypeJSON = "application/json"
+)
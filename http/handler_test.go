@@ -0,0 +1,427 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	. "github.com/gotech-labs/api/http"
+)
+
+func TestHandler(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		method   string
+		response api.Response
+		status   int
+		body     string
+		header   http.Header
+	}{
+		{
+			name:     "ok response",
+			method:   http.MethodGet,
+			response: api.OK(map[string]string{"message": "ok"}),
+			status:   http.StatusOK,
+			body:     "{\"message\":\"ok\"}\n",
+			header: http.Header{
+				"Content-Type":   {"application/json"},
+				"Content-Length": {"17"},
+			},
+		},
+		{
+			name:     "error response",
+			method:   http.MethodPost,
+			response: api.BadRequest(fmt.Errorf("validation error")),
+			status:   http.StatusBadRequest,
+			body:     "",
+			header: http.Header{
+				"Content-Type": {"application/json"},
+			},
+		},
+		{
+			name:     "nil body response",
+			method:   http.MethodGet,
+			response: api.OK(nil),
+			status:   http.StatusOK,
+			body:     "",
+			header: http.Header{
+				"Content-Length": {"0"},
+			},
+		},
+		{
+			name:     "no content response",
+			method:   http.MethodDelete,
+			response: api.NoContent(),
+			status:   http.StatusNoContent,
+			body:     "",
+			header:   http.Header{},
+		},
+		{
+			name:     "head request",
+			method:   http.MethodHead,
+			response: api.OK(map[string]string{"message": "ok"}),
+			status:   http.StatusOK,
+			body:     "",
+			header: http.Header{
+				"Content-Type":   {"application/json"},
+				"Content-Length": {"17"},
+			},
+		},
+		{
+			name:   "multi value header",
+			method: http.MethodGet,
+			response: api.OK(map[string]string{"message": "ok"}).
+				AddHeader("Vary", "Origin").
+				AddHeader("Vary", "Accept-Encoding").
+				WithCookie(&http.Cookie{Name: "session", Value: "abc"}).
+				WithCookie(&http.Cookie{Name: "theme", Value: "dark"}),
+			status: http.StatusOK,
+			body:   "{\"message\":\"ok\"}\n",
+			header: http.Header{
+				"Vary":       {"Origin", "Accept-Encoding"},
+				"Set-Cookie": {"session=abc", "theme=dark"},
+			},
+		},
+		{
+			name:     "custom header",
+			method:   http.MethodGet,
+			response: api.OK(map[string]string{"message": "ok"}).WithHeader("X-Custom-Id", "123"),
+			status:   http.StatusOK,
+			body:     "{\"message\":\"ok\"}\n",
+			header: http.Header{
+				"Content-Type":   {"application/json"},
+				"Content-Length": {"17"},
+				"X-Custom-Id":    {"123"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				req     = httptest.NewRequest(test.method, "/events", nil)
+				rec     = httptest.NewRecorder()
+				handler = func(ctx context.Context, req api.Request) api.Response {
+					return test.response
+				}
+			)
+			NewHandler(handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			if test.body != "" {
+				assert.Equal(t, test.body, rec.Body.String())
+			}
+			if test.method == http.MethodHead || test.status == http.StatusNoContent {
+				assert.Empty(t, rec.Body.String())
+			}
+			for key := range test.header {
+				assert.Equal(t, test.header.Values(key), rec.Header().Values(key))
+			}
+			if test.status == http.StatusNoContent {
+				assert.Empty(t, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestHandlerMiddlewareOrder(t *testing.T) {
+	var (
+		calls      []string
+		middleware = func(name string) api.MiddlewareFunc {
+			return func(next api.HandlerFunc) api.HandlerFunc {
+				return func(ctx context.Context, req api.Request) api.Response {
+					calls = append(calls, name)
+					return next(ctx, req)
+				}
+			}
+		}
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			calls = append(calls, "handler")
+			return api.OK(req.PathParameter("id"))
+		}
+		req = httptest.NewRequest(http.MethodGet, "/events", strings.NewReader(""))
+		rec = httptest.NewRecorder()
+	)
+	NewHandler(handler, middleware("first"), middleware("second")).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestHandlerContentNegotiation(t *testing.T) {
+	handler := func(ctx context.Context, req api.Request) api.Response {
+		switch req.Path() {
+		case "/export":
+			return api.OK([][]string{{"id"}, {"1"}}).WithEncoding("text/csv")
+		case "/error":
+			return api.NotFound(api.RoutingError.New("Event not found"))
+		case "/events/123":
+			return api.OK(map[string]interface{}{"id": 123}).WithHeader("X-Request-Id", "abc")
+		}
+		return api.OK("ok")
+	}
+	browser := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	for _, test := range []struct {
+		name        string
+		path        string
+		accept      string
+		status      int
+		contentType string
+		body        string
+		headers     http.Header
+	}{
+		{
+			name:        "default json",
+			path:        "/events",
+			accept:      "",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        "{\"message\":\"ok\"}\n",
+		},
+		{
+			name:        "plain text",
+			path:        "/events",
+			accept:      "text/plain",
+			status:      http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			body:        "ok",
+		},
+		{
+			name:        "forced encoding",
+			path:        "/export",
+			accept:      "application/json",
+			status:      http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			body:        "id\n1\n",
+		},
+		{
+			name:        "browser",
+			path:        "/events",
+			accept:      browser,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        "{\"message\":\"ok\"}\n",
+		},
+		{
+			name:        "browser error",
+			path:        "/error",
+			accept:      browser,
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			name:        "xml",
+			path:        "/events",
+			accept:      "application/xml",
+			status:      http.StatusOK,
+			contentType: "application/xml",
+			body:        `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><message>ok</message></response>`,
+		},
+		{
+			name:        "csv falls back to json",
+			path:        "/events/123",
+			accept:      "text/csv, */*;q=0.1",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        "{\"id\":123}\n",
+		},
+		{
+			name:        "csv without fallback",
+			path:        "/events/123",
+			accept:      "text/csv",
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json",
+			headers:     http.Header{"X-Request-Id": {"abc"}},
+		},
+		{
+			name:        "csv error falls back to json",
+			path:        "/error",
+			accept:      "text/csv",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			name:        "not acceptable",
+			path:        "/events",
+			accept:      "image/png",
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				req = httptest.NewRequest(http.MethodGet, test.path, nil)
+				rec = httptest.NewRecorder()
+			)
+			req.Header.Set("Accept", test.accept)
+			NewHandler(handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			assert.Equal(t, test.contentType, rec.Header().Get("Content-Type"))
+			if test.body != "" {
+				assert.Equal(t, test.body, rec.Body.String())
+			}
+			for key, values := range test.headers {
+				assert.Equal(t, values, rec.Header()[key])
+			}
+		})
+	}
+}
+
+func TestHandlerStream(t *testing.T) {
+	var (
+		status     int
+		middleware = func(next api.HandlerFunc) api.HandlerFunc {
+			return func(ctx context.Context, req api.Request) api.Response {
+				resp := next(ctx, req)
+				status = resp.Status()
+				return resp
+			}
+		}
+		modtime = time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC)
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			switch req.Path() {
+			case "/download":
+				return api.Attachment("events.txt", strings.NewReader("0123456789"), modtime)
+			case "/broken":
+				return api.StreamFunc(http.StatusOK, "text/plain", func(w io.Writer) error {
+					return fmt.Errorf("connection error")
+				})
+			default:
+				return api.Stream(http.StatusOK, "text/plain", strings.NewReader("streaming body"))
+			}
+		}
+	)
+	for _, test := range []struct {
+		name    string
+		method  string
+		path    string
+		header  http.Header
+		status  int
+		body    string
+		headers http.Header
+	}{
+		{
+			name:   "chunked stream",
+			method: http.MethodGet,
+			path:   "/stream",
+			status: http.StatusOK,
+			body:   "streaming body",
+			headers: http.Header{
+				"Content-Type": {"text/plain"},
+			},
+		},
+		{
+			name:   "head stream",
+			method: http.MethodHead,
+			path:   "/stream",
+			status: http.StatusOK,
+			body:   "",
+		},
+		{
+			name:   "download",
+			method: http.MethodGet,
+			path:   "/download",
+			status: http.StatusOK,
+			body:   "0123456789",
+			headers: http.Header{
+				"Content-Type":        {"text/plain; charset=utf-8"},
+				"Content-Disposition": {"attachment; filename=events.txt"},
+				"Content-Length":      {"10"},
+				"Accept-Ranges":       {"bytes"},
+			},
+		},
+		{
+			name:   "range request",
+			method: http.MethodGet,
+			path:   "/download",
+			header: http.Header{"Range": {"bytes=2-5"}},
+			status: http.StatusPartialContent,
+			body:   "2345",
+			headers: http.Header{
+				"Content-Range": {"bytes 2-5/10"},
+			},
+		},
+		{
+			name:   "unsatisfiable range",
+			method: http.MethodGet,
+			path:   "/download",
+			header: http.Header{"Range": {"bytes=20-30"}},
+			status: http.StatusRequestedRangeNotSatisfiable,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				req = httptest.NewRequest(test.method, test.path, nil)
+				rec = httptest.NewRecorder()
+			)
+			for key, values := range test.header {
+				req.Header[key] = values
+			}
+			NewHandler(handler, middleware).ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			assert.Equal(t, http.StatusOK, status)
+			if test.body != "" || test.method == http.MethodHead {
+				assert.Equal(t, test.body, rec.Body.String())
+			}
+			for key := range test.headers {
+				assert.Equal(t, test.headers.Values(key), rec.Header().Values(key))
+			}
+		})
+	}
+
+	t.Run("close file after download", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "events")
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, _ = file.WriteString("0123456789")
+		var (
+			req = httptest.NewRequest(http.MethodGet, "/download", nil)
+			rec = httptest.NewRecorder()
+		)
+		NewHandler(func(ctx context.Context, req api.Request) api.Response {
+			return api.Attachment("events.txt", file, modtime)
+		}).ServeHTTP(rec, req)
+
+		assert.Equal(t, "0123456789", rec.Body.String())
+		assert.ErrorIs(t, file.Close(), os.ErrClosed)
+	})
+
+	t.Run("stream error aborts the response", func(t *testing.T) {
+		var (
+			req = httptest.NewRequest(http.MethodGet, "/broken", nil)
+			rec = httptest.NewRecorder()
+		)
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			NewHandler(handler).ServeHTTP(rec, req)
+		})
+	})
+}
+
+func TestHandlerEventStream(t *testing.T) {
+	var (
+		req     = httptest.NewRequest(http.MethodGet, "/events", nil)
+		rec     = httptest.NewRecorder()
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			events := make(chan api.Event, 1)
+			events <- api.Event{ID: "1", Data: "hello"}
+			close(events)
+			return api.EventStream(ctx, events)
+		}
+	)
+	NewHandler(handler).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 1\ndata: hello\n\n", rec.Body.String())
+	assert.True(t, rec.Flushed)
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Host returns the host and the optional port requested by the client. Behind a trusted proxy
+// it is resolved from the Forwarded and X-Forwarded-Host headers.
+func (r *request) Host() string {
+	if host := r.forwarded("host", headerXForwardedHost); host != "" {
+		return host
+	}
+	if r.Request.Host != "" {
+		return r.Request.Host
+	}
+	return r.Request.URL.Host
+}
+
+// Domain returns the host without the port.
+func (r *request) Domain() string {
+	host, _ := splitHostPort(r.Host())
+	return host
+}
+
+// Scheme returns "https" or "http". Behind a trusted proxy it is resolved from the Forwarded
+// and X-Forwarded-Proto headers.
+func (r *request) Scheme() string {
+	if proto := r.forwarded("proto", headerXForwardedProto); proto != "" {
+		return strings.ToLower(proto)
+	}
+	if r.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Port returns the port of the host, or the default port of the scheme.
+func (r *request) Port() string {
+	if _, port := splitHostPort(r.Host()); port != "" {
+		return port
+	}
+	if port := r.forwarded("", headerXForwardedPort); port != "" {
+		return port
+	}
+	if r.Scheme() == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// URL resolves the reference against the absolute url of the request, e.g. URL("/events/1").
+// An empty reference returns the url of the request itself.
+func (r *request) URL(ref string) string {
+	base := &url.URL{
+		Scheme:   r.Scheme(),
+		Host:     r.Host(),
+		Path:     r.Request.URL.Path,
+		RawPath:  r.Request.URL.RawPath,
+		RawQuery: r.Request.URL.RawQuery,
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return base.String()
+	}
+	return base.ResolveReference(u).String()
+}
+
+func (r *request) fromTrustedProxy() bool {
+	return r.trustedProxies.Contains(parseIP(r.RemoteAddr))
+}
+
+// forwarded returns the parameter of the Forwarded header, or the value of the X-Forwarded header without it,
+// set by the outermost trusted proxy. Values before it may be sent by the client and are ignored,
+// in the same way as the hops walked by ClientIP.
+func (r *request) forwarded(param, header string) string {
+	if !r.fromTrustedProxy() {
+		return ""
+	}
+	if elements := forwardedElements(r.Request.Header.Values(headerForwarded)); param != "" && hasParam(elements, param) {
+		hops := make([]string, len(elements))
+		for i, element := range elements {
+			hops[i] = element["for"]
+		}
+		// nearer proxies may omit the parameter
+		for i := trustedIndex(clientHop(hops, r.trustedProxies), len(hops), len(elements)); i < len(elements); i++ {
+			if value := elements[i][param]; value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	values := forwardedList(r.Request.Header.Values(header))
+	if len(values) == 0 {
+		return ""
+	}
+	hops := forwardedList(r.Request.Header.Values(headerXForwardedFor))
+	return values[trustedIndex(clientHop(hops, r.trustedProxies), len(hops), len(values))]
+}
+
+// trustedIndex returns the index of the value appended by the outermost trusted proxy, given the index of the client hop.
+// Each trusted proxy appends one hop and one value, so the values are aligned with the hops from the right.
+func trustedIndex(client, hops, values int) int {
+	i := values - (hops - client)
+	switch {
+	case i < 0:
+		return 0
+	case i >= values:
+		// the nearest hop can not be walked through, so only the nearest value is trusted
+		return values - 1
+	}
+	return i
+}
+
+// splitHostPort splits the host into the domain and the optional port, e.g. "[2001:db8::1]:8080".
+func splitHostPort(host string) (string, string) {
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		return h, port
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"), ""
+}
+
+const (
+	headerXForwardedHost  = "X-Forwarded-Host"
+	headerXForwardedProto = "X-Forwarded-Proto"
+	headerXForwardedPort  = "X-Forwarded-Port"
+)
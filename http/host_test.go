@@ -0,0 +1,158 @@
+package http_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api/http"
+)
+
+func TestRequestHost(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, tc := range []struct {
+		name       string
+		target     string
+		host       string
+		remoteAddr string
+		tls        bool
+		headers    map[string][]string
+		expected   [5]string // host, domain, scheme, port, url
+	}{
+		{
+			name:       "host header",
+			target:     "/events?page=2",
+			host:       "api.example.com",
+			remoteAddr: "203.0.113.1:1234",
+			expected:   [5]string{"api.example.com", "api.example.com", "http", "80", "http://api.example.com/events?page=2"},
+		},
+		{
+			name:       "host header with port over tls",
+			target:     "/events",
+			host:       "api.example.com:8443",
+			remoteAddr: "203.0.113.1:1234",
+			tls:        true,
+			expected:   [5]string{"api.example.com:8443", "api.example.com", "https", "8443", "https://api.example.com:8443/events"},
+		},
+		{
+			name:       "ipv6 host",
+			target:     "/events",
+			host:       "[2001:db8::1]:8080",
+			remoteAddr: "203.0.113.1:1234",
+			expected:   [5]string{"[2001:db8::1]:8080", "2001:db8::1", "http", "8080", "http://[2001:db8::1]:8080/events"},
+		},
+		{
+			name:       "forwarding headers from untrusted peer",
+			target:     "/events",
+			host:       "api.example.com",
+			remoteAddr: "203.0.113.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-Host":  {"evil.example.com"},
+				"X-Forwarded-Proto": {"https"},
+			},
+			expected: [5]string{"api.example.com", "api.example.com", "http", "80", "http://api.example.com/events"},
+		},
+		{
+			name:       "x-forwarded headers from trusted proxy",
+			target:     "/events",
+			host:       "internal:8080",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For":   {"198.51.100.1, 10.0.0.2"},
+				"X-Forwarded-Host":  {"www.example.com, internal"},
+				"X-Forwarded-Proto": {"HTTPS, http"},
+			},
+			expected: [5]string{"www.example.com", "www.example.com", "https", "443", "https://www.example.com/events"},
+		},
+		{
+			name:       "x-forwarded headers spoofed by client",
+			target:     "/events",
+			host:       "internal:8080",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For":   {"198.51.100.1"},
+				"X-Forwarded-Host":  {"evil.example.com, www.example.com"},
+				"X-Forwarded-Proto": {"http, https"},
+				"X-Forwarded-Port":  {"8080, 443"},
+			},
+			expected: [5]string{"www.example.com", "www.example.com", "https", "443", "https://www.example.com/events"},
+		},
+		{
+			name:       "x-forwarded headers of nearest proxy",
+			target:     "/events",
+			host:       "internal:8080",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-Host": {"evil.example.com, www.example.com"},
+			},
+			expected: [5]string{"www.example.com", "www.example.com", "http", "80", "http://www.example.com/events"},
+		},
+		{
+			name:       "x-forwarded-port from trusted proxy",
+			target:     "/events",
+			host:       "internal",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-Port": {"8080"},
+			},
+			expected: [5]string{"internal", "internal", "http", "8080", "http://internal/events"},
+		},
+		{
+			name:       "forwarded header from trusted proxy",
+			target:     "/events",
+			host:       "internal:8080",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"Forwarded":        {`for=198.51.100.1;host="www.example.com:8443";proto=https`},
+				"X-Forwarded-Host": {"other.example.com"},
+			},
+			expected: [5]string{"www.example.com:8443", "www.example.com", "https", "8443", "https://www.example.com:8443/events"},
+		},
+		{
+			name:       "forwarded header spoofed by client",
+			target:     "/events",
+			host:       "internal:8080",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"Forwarded": {
+					`for=192.0.2.1;host=evil.example.com;proto=http`,
+					`for=198.51.100.1;host=www.example.com;proto=https, for=10.0.0.2;host=internal`,
+				},
+			},
+			expected: [5]string{"www.example.com", "www.example.com", "https", "443", "https://www.example.com/events"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			r.Host = tc.host
+			r.RemoteAddr = tc.remoteAddr
+			if !tc.tls {
+				r.TLS = nil
+			} else {
+				r.TLS = &tls.ConnectionState{}
+			}
+			for key, values := range tc.headers {
+				r.Header[key] = values
+			}
+			req := NewRequest(r, WithTrustedProxies(proxies))
+			assert.Equal(t, tc.expected, [5]string{req.Host(), req.Domain(), req.Scheme(), req.Port(), req.URL("")})
+		})
+	}
+
+	t.Run("absolute links", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/events/1?page=2", nil)
+		r.Host = "api.example.com"
+		req := NewRequest(r)
+
+		assert.Equal(t, "http://api.example.com/events/2", req.URL("/events/2"))
+		assert.Equal(t, "http://api.example.com/events/comments", req.URL("comments"))
+		assert.Equal(t, "http://api.example.com/events/1?page=3", req.URL("?page=3"))
+		assert.Equal(t, "https://cdn.example.com/a.png", req.URL("https://cdn.example.com/a.png"))
+	})
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gotech-labs/core/errors"
+)
+
+// TrustedProxies is the list of networks of the reverse proxies whose forwarding headers are trusted.
+type TrustedProxies struct {
+	networks []*net.IPNet
+}
+
+// NewTrustedProxies returns the trusted proxies of the CIDRs, e.g. "10.0.0.0/8" and "fd00::/8".
+// Plain IP addresses are trusted as single hosts.
+func NewTrustedProxies(cidrs ...string) (*TrustedProxies, error) {
+	p := &TrustedProxies{}
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, errors.ValidationError.New(fmt.Sprintf("Invalid trusted proxy: cidr=%v", cidr))
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			p.networks = append(p.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.ValidationError.Wrapf(err,
+				"Invalid trusted proxy: cidr=%v, error=%v", cidr, err.Error())
+		}
+		p.networks = append(p.networks, network)
+	}
+	return p, nil
+}
+
+// Contains reports whether the ip belongs to a trusted proxy.
+func (p *TrustedProxies) Contains(ip net.IP) bool {
+	if p == nil || ip == nil {
+		return false
+	}
+	for _, network := range p.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTrustedProxies is the trusted proxies of the requests created by NewRequest.
+// No proxy is trusted by default, so forwarding headers are ignored.
+var DefaultTrustedProxies = &TrustedProxies{}
+
+// WithTrustedProxies sets the trusted proxies of the request.
+func WithTrustedProxies(proxies *TrustedProxies) RequestOption {
+	return func(r *request) {
+		r.trustedProxies = proxies
+	}
+}
+
+// clientIP resolves the client address. Forwarding headers are used only when the peer is a trusted proxy:
+// the hops of the Forwarded header, or X-Forwarded-For without it, are walked from right to left
+// and the first untrusted hop is the client. X-Real-Ip is used when neither is present.
+func clientIP(r *http.Request, proxies *TrustedProxies) string {
+	remote := parseIP(r.RemoteAddr)
+	if remote == nil {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return host
+	}
+	if !proxies.Contains(remote) {
+		return remote.String()
+	}
+	hops := forwardedHops(r)
+	if len(hops) == 0 {
+		if ip := parseIP(r.Header.Get(headerXRealIP)); ip != nil {
+			return ip.String()
+		}
+		return remote.String()
+	}
+	if i := clientHop(hops, proxies); i < len(hops) {
+		return parseIP(hops[i]).String()
+	}
+	return remote.String()
+}
+
+// forwardedHops returns the for parameters of the Forwarded header, or X-Forwarded-For without it.
+func forwardedHops(r *http.Request) []string {
+	if elements := forwardedElements(r.Header.Values(headerForwarded)); hasParam(elements, "for") {
+		hops := make([]string, len(elements))
+		for i, element := range elements {
+			hops[i] = element["for"]
+		}
+		return hops
+	}
+	return forwardedList(r.Header.Values(headerXForwardedFor))
+}
+
+// clientHop walks the hops from right to left and returns the index of the first untrusted one,
+// or of the leftmost one when all are trusted. It returns len(hops) when the nearest hop can not be walked through.
+// The hops at and after the index were appended by trusted proxies.
+func clientHop(hops []string, proxies *TrustedProxies) int {
+	client := len(hops)
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseIP(hops[i])
+		if ip == nil {
+			// obfuscated or unknown hops can not be walked through
+			break
+		}
+		client = i
+		if !proxies.Contains(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// forwardedElements returns the parameters of each element of the Forwarded header (RFC 7239)
+// with lower case names and unquoted values.
+func forwardedElements(values []string) []map[string]string {
+	var elements []map[string]string
+	for _, value := range values {
+		for _, element := range splitQuoted(value, ',') {
+			params := map[string]string{}
+			for _, pair := range splitQuoted(element, ';') {
+				if i := strings.Index(pair, "="); i >= 0 {
+					key := strings.ToLower(strings.TrimSpace(pair[:i]))
+					params[key] = strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+				}
+			}
+			elements = append(elements, params)
+		}
+	}
+	return elements
+}
+
+func hasParam(elements []map[string]string, name string) bool {
+	for _, element := range elements {
+		if element[name] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func forwardedList(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// splitQuoted splits the value by the separator outside of quoted strings.
+func splitQuoted(value string, sep byte) []string {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if quoted {
+				i++
+			}
+		case sep:
+			if !quoted {
+				parts = append(parts, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// parseIP parses the address with an optional port, e.g. "192.0.2.1:80", "[2001:db8::1]:80" and "2001:db8::1".
+func parseIP(addr string) net.IP {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if i := strings.LastIndex(addr, "%"); i >= 0 {
+		// drop the zone of link-local addresses
+		addr = addr[:i]
+	}
+	return net.ParseIP(addr)
+}
+
+const (
+	headerForwarded = "Forwarded"
+)
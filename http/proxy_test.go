@@ -0,0 +1,110 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api/http"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8", "fd00::/8", "192.0.2.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		expected   string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "203.0.113.1:1234",
+			expected:   "203.0.113.1",
+		},
+		{
+			name:       "spoofed headers from untrusted peer",
+			remoteAddr: "203.0.113.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"198.51.100.1"},
+				"X-Real-Ip":       {"198.51.100.2"},
+				"Forwarded":       {"for=198.51.100.3"},
+			},
+			expected: "203.0.113.1",
+		},
+		{
+			name:       "x-forwarded-for walked from right to left",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"198.51.100.1, 203.0.113.1", "10.0.0.2"},
+			},
+			expected: "203.0.113.1",
+		},
+		{
+			name:       "every hop is trusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"},
+			},
+			expected: "10.0.0.3",
+		},
+		{
+			name:       "forwarded header takes precedence",
+			remoteAddr: "192.0.2.1:1234",
+			headers: map[string][]string{
+				"Forwarded":       {`for=198.51.100.1;proto=https, for="[2001:db8:cafe::17]:4711"`},
+				"X-Forwarded-For": {"203.0.113.1"},
+			},
+			expected: "2001:db8:cafe::17",
+		},
+		{
+			name:       "unknown forwarded hop",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"Forwarded": {"for=198.51.100.1, for=unknown, for=10.0.0.2"},
+			},
+			expected: "10.0.0.2",
+		},
+		{
+			name:       "x-real-ip from trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Real-Ip": {"203.0.113.1"},
+			},
+			expected: "203.0.113.1",
+		},
+		{
+			name:       "ipv6 peer",
+			remoteAddr: "[fd00::1]:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"2001:db8::1"},
+			},
+			expected: "2001:db8::1",
+		},
+		{
+			name:       "ipv6 peer with zone",
+			remoteAddr: "[fe80::1%eth0]:1234",
+			expected:   "fe80::1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for key, values := range tc.headers {
+				r.Header[key] = values
+			}
+			assert.Equal(t, tc.expected, NewRequest(r, WithTrustedProxies(proxies)).ClientIP())
+		})
+	}
+
+	t.Run("invalid cidr", func(t *testing.T) {
+		_, err := NewTrustedProxies("10.0.0.0/33")
+		assert.Error(t, err)
+		_, err = NewTrustedProxies("unknown")
+		assert.Error(t, err)
+	})
+}
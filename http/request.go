@@ -1,32 +1,74 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
-	"net"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/gotech-labs/api"
-	"github.com/gotech-labs/core/log"
+	"golang.org/x/xerrors"
 )
 
-func NewRequest(req *http.Request) api.Request {
-	return &request{
-		Request:    req,
-		body:       nil,
-		query:      req.URL.Query(),
-		pathParams: mux.Vars(req),
+// DefaultMaxBodySize is the maximum size of request bodies buffered by NewRequest.
+// Multipart forms that are not read as the body are limited by DefaultMaxMultipartSize instead.
+var DefaultMaxBodySize int64 = 10 << 20
+
+// RequestOption configures the request created by NewRequest.
+type RequestOption func(*request)
+
+// WithMaxBodySize sets the maximum size of the request body.
+func WithMaxBodySize(size int64) RequestOption {
+	return func(r *request) {
+		r.maxBodySize = size
+	}
+}
+
+func NewRequest(req *http.Request, opts ...RequestOption) api.Request {
+	r := &request{
+		Request:     req,
+		body:        nil,
+		query:       req.URL.Query(),
+		pathParams:  mux.Vars(req),
+		maxBodySize: DefaultMaxBodySize,
+
+		maxMultipartSize:   DefaultMaxMultipartSize,
+		maxMultipartMemory: DefaultMaxMultipartMemory,
+		maxFileSize:        DefaultMaxFileSize,
+		bindOptions:        DefaultBindOptions,
+		trustedProxies:     DefaultTrustedProxies,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 type request struct {
 	*http.Request
-	body       []byte
-	query      url.Values
-	pathParams map[string]string
+	body        []byte
+	bodyErr     error
+	bodyOnce    sync.Once
+	maxBodySize int64
+	query       url.Values
+	pathParams  map[string]string
+
+	form               url.Values
+	multipartForm      *multipart.Form
+	formErr            error
+	formOnce           sync.Once
+	maxMultipartSize   int64
+	maxMultipartMemory int64
+	maxFileSize        int64
+
+	bindOptions    BindOptions
+	trustedProxies *TrustedProxies
 }
 
 func (r *request) Method() string {
@@ -34,15 +76,48 @@ func (r *request) Method() string {
 }
 
 func (r *request) Path() string {
-	return r.URL.Path
+	return r.Request.URL.Path
 }
 
+// Body returns the buffered request body, or nil when it could not be read. See ReadBody for the error.
 func (r *request) Body() []byte {
-	b, err := io.ReadAll(r.Request.Body)
+	b, _ := r.ReadBody()
+	return b
+}
+
+// ReadBody reads the request body once and buffers it, so that it can be read repeatedly
+// by middlewares and Bind.
+func (r *request) ReadBody() ([]byte, error) {
+	r.bodyOnce.Do(func() {
+		r.body, r.bodyErr = r.readBody()
+		r.Request.Body = io.NopCloser(bytes.NewReader(r.body))
+	})
+	return r.body, r.bodyErr
+}
+
+func (r *request) readBody() ([]byte, error) {
+	if r.Request.Body == nil || r.Request.Body == http.NoBody {
+		return nil, nil
+	}
+	defer r.Request.Body.Close()
+	if r.maxBodySize > 0 && r.Request.ContentLength > r.maxBodySize {
+		return nil, api.BodyTooLargeError.New(fmt.Sprintf(
+			"Request body too large: size=%v, limit=%v", r.Request.ContentLength, r.maxBodySize))
+	}
+	reader := io.Reader(r.Request.Body)
+	if r.maxBodySize > 0 {
+		reader = io.LimitReader(reader, r.maxBodySize+1)
+	}
+	b, err := io.ReadAll(reader)
 	if err != nil {
-		log.Panic().Err(err).Msg("Failed to read request body")
+		return nil, api.BindingError.Wrapf(err,
+			"Failed to read request body: error=%v", err.Error())
 	}
-	return b
+	if r.maxBodySize > 0 && int64(len(b)) > r.maxBodySize {
+		return nil, api.BodyTooLargeError.New(fmt.Sprintf(
+			"Request body too large: limit=%v", r.maxBodySize))
+	}
+	return b, nil
 }
 
 func (r *request) Headers() map[string][]string {
@@ -61,6 +136,8 @@ func (r *request) QueryParameter(key string) string {
 	return r.query.Get(key)
 }
 
+// QueryParameters returns the query parameters with repeated values joined by commas. Use QueryValues
+// for values that may contain commas.
 func (r *request) QueryParameters() map[string]string {
 	params := make(map[string]string, 0)
 	for key, values := range r.query {
@@ -69,6 +146,11 @@ func (r *request) QueryParameters() map[string]string {
 	return params
 }
 
+// QueryValues returns all values of the repeated query parameter, e.g. ?tag=a&tag=b.
+func (r *request) QueryValues(key string) []string {
+	return r.query[key]
+}
+
 func (r *request) PathParameter(key string) string {
 	if value, ok := r.pathParams[key]; ok {
 		return value
@@ -76,19 +158,9 @@ func (r *request) PathParameter(key string) string {
 	return ""
 }
 
+// ClientIP returns the client address. Forwarding headers are trusted only from the trusted proxies.
 func (r *request) ClientIP() string {
-	if ip := r.Request.Header.Get(headerXForwardedFor); ip != "" {
-		i := strings.IndexAny(ip, ",")
-		if i > 0 {
-			return strings.TrimSpace(ip[:i])
-		}
-		return ip
-	}
-	if ip := r.Request.Header.Get(headerXRealIP); ip != "" {
-		return ip
-	}
-	ra, _, _ := net.SplitHostPort(r.RemoteAddr)
-	return ra
+	return clientIP(r.Request, r.trustedProxies)
 }
 
 func (r *request) UserAgent() string {
@@ -99,36 +171,64 @@ func (r *request) Referer() string {
 	return r.Request.Referer()
 }
 
-func (r *request) Domain() string {
-	return r.Request.URL.Host
-}
-
 func (r *request) Protocol() string {
 	return r.Request.Proto
 }
 
-func (r *request) Host() string {
-	return r.Request.URL.Host
-}
-
 func (r *request) ContentLength() int64 {
 	return r.Request.ContentLength
 }
 
+// Bind decodes the JSON body into the object, then fills the fields tagged with path, query and header.
+// The body may be empty when the object has such tagged fields.
+// Url-encoded and multipart forms are bound to the fields tagged with form instead of the JSON body;
+// uploaded files are bound to *api.FormFile and []*api.FormFile fields.
+// The bound object is validated with api.Validate.
 func (r *request) Bind(obj interface{}) error {
-	err := json.NewDecoder(r.Request.Body).Decode(obj)
+	if err := r.bind(obj); err != nil {
+		return err
+	}
+	return api.Validate(obj)
+}
+
+func (r *request) bind(obj interface{}) error {
+	if r.isForm() {
+		if err := r.parseForm(); err != nil {
+			return err
+		}
+		if errs := api.BindParams(r, obj); len(errs) > 0 {
+			return api.BindingError.Wrapf(errs,
+				"Failed to binding request: %v", errs.Error())
+		}
+		return nil
+	}
+	body, err := r.ReadBody()
 	if err != nil {
-		if ute, ok := err.(*json.UnmarshalTypeError); ok {
-			return api.BindingError.Wrapf(err,
-				"Unmarshal type error: expected=%v, got=%v, field=%v, offset=%v", ute.Type, ute.Value, ute.Field, ute.Offset)
-		} else if se, ok := err.(*json.SyntaxError); ok {
-			return api.BindingError.Wrapf(err,
-				"Syntax error: offset=%v, error=%v", se.Offset, se.Error())
+		return err
+	}
+	var (
+		hasParams = api.HasParamTags(obj)
+		bodyErr   error
+	)
+	if len(body) > 0 || !hasParams {
+		bodyErr = decodeJSON(body, obj, r.bindOptions)
+	}
+	if !hasParams {
+		return bodyErr
+	}
+	errs := api.BindParams(r, obj)
+	if len(errs) == 0 {
+		return bodyErr
+	}
+	if bodyErr != nil {
+		field := ""
+		if ute, ok := xerrors.Unwrap(bodyErr).(*json.UnmarshalTypeError); ok {
+			field = ute.Field
 		}
-		return api.BindingError.Wrapf(err,
-			"Failed to binding object: error=%v", err.Error())
+		errs = append(api.FieldErrors{{Field: field, Source: "body", Reason: bodyErr.Error()}}, errs...)
 	}
-	return nil
+	return api.BindingError.Wrapf(errs,
+		"Failed to binding request: %v", errs.Error())
 }
 
 const (
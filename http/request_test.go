@@ -1,12 +1,15 @@
 package http_test
 
 import (
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/gotech-labs/api"
 	. "github.com/gotech-labs/api/http"
 	apitest "github.com/gotech-labs/api/testing"
 )
@@ -134,3 +137,183 @@ func TestBindRequest(t *testing.T) {
 		}
 	})
 }
+
+func TestRequestBodyCache(t *testing.T) {
+	input := struct {
+		ID   int
+		Name string
+	}{}
+
+	t.Run("replayable body", func(t *testing.T) {
+		var (
+			body = []byte(`{"id": 12345, "name": "Michael Jordan"}`)
+			req  = NewRequest(apitest.RequestBuilder{
+				Method: http.MethodPost,
+				Path:   "/events",
+				Body:   body,
+			}.Build())
+		)
+		assert.Equal(t, body, req.Body())
+		assert.Equal(t, body, req.Body())
+		if assert.NoError(t, req.Bind(&input)) {
+			assert.Equal(t, 12345, input.ID)
+		}
+		if assert.NoError(t, req.Bind(&input)) {
+			assert.Equal(t, "Michael Jordan", input.Name)
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		req := NewRequest(apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+			Body:   []byte(`{"id": 12345, "name": "Michael Jordan"}`),
+		}.Build(), WithMaxBodySize(10))
+
+		assert.Nil(t, req.Body())
+		_, err := req.ReadBody()
+		if assert.Error(t, err) {
+			assert.Equal(t, "Request body too large: size=39, limit=10", err.Error())
+			assert.Equal(t, http.StatusRequestEntityTooLarge, api.ErrorStatus(err))
+		}
+		assert.Error(t, req.Bind(&input))
+	})
+
+	t.Run("body too large without content length", func(t *testing.T) {
+		r := apitest.RequestBuilder{
+			Method: http.MethodPost,
+			Path:   "/events",
+		}.Build()
+		r.Body = io.NopCloser(strings.NewReader(`{"id": 12345, "name": "Michael Jordan"}`))
+		r.ContentLength = -1
+		req := NewRequest(r, WithMaxBodySize(10))
+
+		_, err := req.ReadBody()
+		if assert.Error(t, err) {
+			assert.Equal(t, "Request body too large: limit=10", err.Error())
+		}
+	})
+}
+
+func TestBindRequestParams(t *testing.T) {
+	type Input struct {
+		ID      int        `path:"id"`
+		Limit   uint       `query:"limit"`
+		Active  *bool      `query:"active"`
+		Tags    []string   `query:"tags"`
+		Since   time.Time  `query:"since"`
+		Until   *time.Time `query:"until" layout:"2006-01-02"`
+		TraceID string     `header:"X-Trace-Id"`
+		Name    string     `json:"name"`
+	}
+
+	t.Run("success binding", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method:     http.MethodPost,
+				Path:       "/events/123?tags=a,b&tags=c",
+				Body:       []byte(`{"name": "Michael Jordan"}`),
+				PathParams: map[string]string{"id": "123"},
+				QueryParams: map[string]string{
+					"limit":  "10",
+					"active": "true",
+					"since":  "2022-12-24T00:00:00+09:00",
+					"until":  "2022-12-31",
+				},
+				Headers: map[string][]string{"X-Trace-Id": {"trace-1"}},
+			}.Build())
+		)
+		if assert.NoError(t, req.Bind(&input)) {
+			assert.Equal(t, 123, input.ID)
+			assert.Equal(t, uint(10), input.Limit)
+			if assert.NotNil(t, input.Active) {
+				assert.True(t, *input.Active)
+			}
+			assert.Equal(t, []string{"a,b", "c"}, input.Tags)
+			assert.Equal(t, "2022-12-24T00:00:00+09:00", input.Since.Format(time.RFC3339))
+			if assert.NotNil(t, input.Until) {
+				assert.Equal(t, "2022-12-31", input.Until.Format("2006-01-02"))
+			}
+			assert.Equal(t, "trace-1", input.TraceID)
+			assert.Equal(t, "Michael Jordan", input.Name)
+		}
+	})
+
+	t.Run("success binding (empty body)", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method:     http.MethodGet,
+				Path:       "/events/123",
+				PathParams: map[string]string{"id": "123"},
+			}.Build())
+		)
+		if assert.NoError(t, req.Bind(&input)) {
+			assert.Equal(t, 123, input.ID)
+			assert.Nil(t, input.Active)
+			assert.Nil(t, input.Tags)
+		}
+	})
+
+	t.Run("binding error (every invalid field)", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method:     http.MethodGet,
+				Path:       "/events/abc",
+				PathParams: map[string]string{"id": "abc"},
+				QueryParams: map[string]string{
+					"limit": "-1",
+					"since": "yesterday",
+				},
+			}.Build())
+		)
+		err := req.Bind(&input)
+		if assert.Error(t, err) {
+			assert.Equal(t, http.StatusBadRequest, api.ErrorStatus(err))
+			assert.Equal(t, "Failed to binding request: "+
+				`field=id, source=path, reason=invalid int "abc"; `+
+				`field=limit, source=query, reason=invalid uint "-1"; `+
+				`field=since, source=query, reason=invalid time "yesterday": layout=2006-01-02T15:04:05Z07:00`, err.Error())
+		}
+	})
+}
+
+func TestBindRequestValidation(t *testing.T) {
+	type Input struct {
+		ID   int    `path:"id" validate:"min=1"`
+		Name string `json:"name" validate:"required,max=10"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method:     http.MethodPut,
+				Path:       "/events/1",
+				Body:       []byte(`{"name": "Michael"}`),
+				PathParams: map[string]string{"id": "1"},
+			}.Build())
+		)
+		assert.NoError(t, req.Bind(&input))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var (
+			input Input
+			req   = NewRequest(apitest.RequestBuilder{
+				Method:     http.MethodPut,
+				Path:       "/events/0",
+				Body:       []byte(`{"name": ""}`),
+				PathParams: map[string]string{"id": "0"},
+			}.Build())
+		)
+		err := req.Bind(&input)
+		if assert.Error(t, err) {
+			assert.Equal(t, "Failed to validate request: "+
+				"field=id, source=path, reason=must be at least 1; field=name, reason=required", err.Error())
+			assert.Equal(t, http.StatusBadRequest, api.ErrorStatus(err))
+		}
+	})
+}
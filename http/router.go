@@ -0,0 +1,244 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/gotech-labs/api"
+)
+
+// NewRouter returns a router backed by gorilla/mux.
+// The middlewares, and those added with Use, are applied to every route and to the 404/405 responses.
+// OPTIONS requests to routes without an OPTIONS handler, e.g. CORS preflight requests,
+// are served by the middlewares of the route matching the path, so that they reach
+// the middlewares added with Use and Group as well.
+func NewRouter(middlewares ...api.MiddlewareFunc) *Router {
+	r := &Router{
+		mux:         mux.NewRouter(),
+		prefix:      "",
+		middlewares: middlewares,
+	}
+	r.mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.errorHandlers().notFound.ServeHTTP(w, req)
+	})
+	r.mux.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.errorHandlers().methodNotAllowed.ServeHTTP(w, req)
+	})
+	return r
+}
+
+// Router registers api handler functions as http routes.
+type Router struct {
+	mux         *mux.Router
+	prefix      string
+	middlewares []api.MiddlewareFunc
+
+	errorsOnce sync.Once
+	errors     *errorHandlers
+}
+
+// errorHandlers serve the 404/405 responses of the router.
+type errorHandlers struct {
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+}
+
+// errorHandlers builds the 404/405 handlers on the first request, so that they include
+// the middlewares added with Use after NewRouter.
+func (r *Router) errorHandlers() *errorHandlers {
+	r.errorsOnce.Do(func() {
+		r.errors = &errorHandlers{
+			notFound:         NewHandler(notFound, r.middlewares...),
+			methodNotAllowed: NewHandler(r.methodNotAllowed, r.middlewares...),
+		}
+	})
+	return r.errors
+}
+
+// Route is a registered route.
+type Route struct {
+	route   *mux.Route
+	handler *handler
+}
+
+// Name sets the name of the route, which is used to build urls by Router.URL.
+func (r *Route) Name(name string) *Route {
+	r.route.Name(name)
+	return r
+}
+
+// WithRequestOptions sets the options of the requests served by the route, e.g. WithBindOptions.
+func (r *Route) WithRequestOptions(opts ...RequestOption) *Route {
+	r.handler.requestOptions = append(r.handler.requestOptions, opts...)
+	return r
+}
+
+// Use appends middlewares applied to the routes registered afterwards.
+func (r *Router) Use(middlewares ...api.MiddlewareFunc) *Router {
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+// Group returns a router that registers routes under the path prefix.
+// The middlewares are applied only to the routes of the group.
+func (r *Router) Group(prefix string, middlewares ...api.MiddlewareFunc) *Router {
+	mws := make([]api.MiddlewareFunc, 0, len(r.middlewares)+len(middlewares))
+	mws = append(mws, r.middlewares...)
+	mws = append(mws, middlewares...)
+	return &Router{
+		mux:         r.mux,
+		prefix:      r.prefix + strings.TrimSuffix(prefix, "/"),
+		middlewares: mws,
+	}
+}
+
+// GET registers the handler function for GET requests.
+func (r *Router) GET(path string, h api.HandlerFunc, middlewares ...api.MiddlewareFunc) *Route {
+	return r.Handle(http.MethodGet, path, h, middlewares...)
+}
+
+// POST registers the handler function for POST requests.
+func (r *Router) POST(path string, h api.HandlerFunc, middlewares ...api.MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPost, path, h, middlewares...)
+}
+
+// PUT registers the handler function for PUT requests.
+func (r *Router) PUT(path string, h api.HandlerFunc, middlewares ...api.MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPut, path, h, middlewares...)
+}
+
+// PATCH registers the handler function for PATCH requests.
+func (r *Router) PATCH(path string, h api.HandlerFunc, middlewares ...api.MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPatch, path, h, middlewares...)
+}
+
+// DELETE registers the handler function for DELETE requests.
+func (r *Router) DELETE(path string, h api.HandlerFunc, middlewares ...api.MiddlewareFunc) *Route {
+	return r.Handle(http.MethodDelete, path, h, middlewares...)
+}
+
+// Handle registers the handler function for the method and path.
+// Path variables use the gorilla/mux syntax, e.g. "/events/{id}".
+// GET routes serve HEAD requests as well, whose body is dropped when the response is written.
+func (r *Router) Handle(method, path string, h api.HandlerFunc, middlewares ...api.MiddlewareFunc) *Route {
+	mws := make([]api.MiddlewareFunc, 0, len(r.middlewares)+len(middlewares))
+	mws = append(mws, r.middlewares...)
+	mws = append(mws, middlewares...)
+	options := NewHandler(r.methodNotAllowed, mws...).(*handler)
+	handler := NewHandler(h, mws...).(*handler)
+	handler.options = options.handlerFunc
+	methods := []string{method}
+	if method == http.MethodGet {
+		methods = append(methods, http.MethodHead)
+	}
+	route := r.mux.
+		Handle(r.prefix+path, handler).
+		Methods(methods...)
+	return &Route{
+		route:   route,
+		handler: handler,
+	}
+}
+
+// URL builds the url of the named route with path variable pairs.
+func (r *Router) URL(name string, pairs ...string) (*url.URL, error) {
+	route := r.mux.Get(name)
+	if route == nil {
+		return nil, api.RoutingError.New(fmt.Sprintf("Route not found: name=%v", name))
+	}
+	u, err := route.URL(pairs...)
+	if err != nil {
+		return nil, api.RoutingError.Wrapf(err,
+			"Failed to build url: name=%v, error=%v", name, err.Error())
+	}
+	return u, nil
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodOptions {
+		if h, vars := r.optionsHandler(req); h != nil {
+			h.ServeHTTP(w, mux.SetURLVars(req, vars))
+			return
+		}
+	}
+	r.mux.ServeHTTP(w, req)
+}
+
+// optionsHandler returns the handler of OPTIONS requests with the middlewares of the route matching the path.
+// The route of Access-Control-Request-Method is preferred. It returns nil when an OPTIONS route is registered
+// or no route matches.
+func (r *Router) optionsHandler(req *http.Request) (http.Handler, map[string]string) {
+	var match mux.RouteMatch
+	if r.mux.Match(req, &match) && match.MatchErr == nil {
+		return nil, nil
+	}
+	methods := routingMethods
+	if method := req.Header.Get(headerAccessControlRequestMethod); method != "" {
+		methods = append([]string{method}, routingMethods...)
+	}
+	for _, method := range methods {
+		probe := *req
+		probe.Method = method
+		var match mux.RouteMatch
+		if !r.mux.Match(&probe, &match) || match.MatchErr != nil {
+			continue
+		}
+		if h, ok := match.Handler.(*handler); ok && h.options != nil {
+			return &handler{
+				handlerFunc:    h.options,
+				requestOptions: h.requestOptions,
+			}, match.Vars
+		}
+	}
+	return nil, nil
+}
+
+func (r *Router) methodNotAllowed(_ context.Context, req api.Request) api.Response {
+	resp := api.MethodNotAllowed(api.RoutingError.New(fmt.Sprintf(
+		"Method not allowed: method=%v, path=%v", req.Method(), req.Path())))
+	if allowed := r.allowedMethods(req.Path()); len(allowed) > 0 {
+		resp = resp.WithHeader(headerAllow, strings.Join(allowed, ", "))
+	}
+	return resp
+}
+
+func (r *Router) allowedMethods(path string) []string {
+	var allowed []string
+	for _, method := range routingMethods {
+		req := &http.Request{
+			Method: method,
+			URL:    &url.URL{Path: path},
+		}
+		var match mux.RouteMatch
+		if r.mux.Match(req, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+func notFound(_ context.Context, req api.Request) api.Response {
+	return api.NotFound(api.RoutingError.New(fmt.Sprintf(
+		"Route not found: method=%v, path=%v", req.Method(), req.Path())))
+}
+
+var (
+	routingMethods = []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+)
+
+const (
+	headerAllow                      = "Allow"
+	headerAccessControlRequestMethod = "Access-Control-Request-Method"
+)
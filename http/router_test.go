@@ -0,0 +1,188 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	. "github.com/gotech-labs/api/http"
+)
+
+func TestRouter(t *testing.T) {
+	var (
+		calls      []string
+		middleware = func(name string) api.MiddlewareFunc {
+			return func(next api.HandlerFunc) api.HandlerFunc {
+				return func(ctx context.Context, req api.Request) api.Response {
+					calls = append(calls, name)
+					return next(ctx, req)
+				}
+			}
+		}
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			return api.OK(map[string]string{
+				"method": req.Method(),
+				"id":     req.PathParameter("id"),
+			})
+		}
+		router = NewRouter(middleware("root"))
+	)
+	router.GET("/health", handler)
+	events := router.Group("/events", middleware("events"))
+	events.GET("/{id}", handler).Name("event")
+	events.POST("", handler)
+	events.PUT("/{id}", handler)
+	events.PATCH("/{id}", handler)
+	events.DELETE("/{id}", handler, middleware("delete"))
+
+	for _, test := range []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+		calls  []string
+		allow  string
+	}{
+		{
+			name:   "root route",
+			method: http.MethodGet,
+			path:   "/health",
+			status: http.StatusOK,
+			body:   `{"id":"","method":"GET"}`,
+			calls:  []string{"root"},
+		},
+		{
+			name:   "group route",
+			method: http.MethodGet,
+			path:   "/events/123",
+			status: http.StatusOK,
+			body:   `{"id":"123","method":"GET"}`,
+			calls:  []string{"root", "events"},
+		},
+		{
+			name:   "group route without path",
+			method: http.MethodPost,
+			path:   "/events",
+			status: http.StatusOK,
+			body:   `{"id":"","method":"POST"}`,
+			calls:  []string{"root", "events"},
+		},
+		{
+			name:   "route middleware",
+			method: http.MethodDelete,
+			path:   "/events/123",
+			status: http.StatusOK,
+			body:   `{"id":"123","method":"DELETE"}`,
+			calls:  []string{"root", "events", "delete"},
+		},
+		{
+			name:   "head of get route",
+			method: http.MethodHead,
+			path:   "/events/123",
+			status: http.StatusOK,
+			calls:  []string{"root", "events"},
+		},
+		{
+			name:   "not found",
+			method: http.MethodGet,
+			path:   "/unknown",
+			status: http.StatusNotFound,
+			calls:  []string{"root"},
+		},
+		{
+			name:   "method not allowed",
+			method: http.MethodPost,
+			path:   "/events/123",
+			status: http.StatusMethodNotAllowed,
+			calls:  []string{"root"},
+			allow:  "GET, HEAD, PUT, PATCH, DELETE",
+		},
+		{
+			name:   "options through route middlewares",
+			method: http.MethodOptions,
+			path:   "/events/123",
+			status: http.StatusMethodNotAllowed,
+			calls:  []string{"root", "events"},
+			allow:  "GET, HEAD, PUT, PATCH, DELETE",
+		},
+		{
+			name:   "options not found",
+			method: http.MethodOptions,
+			path:   "/unknown",
+			status: http.StatusNotFound,
+			calls:  []string{"root"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			calls = nil
+			var (
+				req = httptest.NewRequest(test.method, test.path, nil)
+				rec = httptest.NewRecorder()
+			)
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			if test.body != "" {
+				assert.JSONEq(t, test.body, rec.Body.String())
+			}
+			assert.Equal(t, test.calls, calls)
+			assert.Equal(t, test.allow, rec.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestRouterUseBeforeErrors(t *testing.T) {
+	var (
+		calls      []string
+		middleware = func(next api.HandlerFunc) api.HandlerFunc {
+			return func(ctx context.Context, req api.Request) api.Response {
+				calls = append(calls, req.Method())
+				return next(ctx, req)
+			}
+		}
+		router = NewRouter()
+	)
+	router.Use(middleware)
+	router.GET("/events", func(ctx context.Context, req api.Request) api.Response {
+		return api.OK("ok")
+	})
+	for _, test := range []struct {
+		method string
+		path   string
+		status int
+	}{
+		{method: http.MethodGet, path: "/unknown", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/events", status: http.StatusMethodNotAllowed},
+	} {
+		calls = nil
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+		assert.Equal(t, test.status, rec.Code)
+		assert.Equal(t, []string{test.method}, calls)
+	}
+}
+
+func TestRouterURL(t *testing.T) {
+	router := NewRouter()
+	router.Group("/v1").GET("/events/{id}", func(ctx context.Context, req api.Request) api.Response {
+		return api.NoContent()
+	}).Name("event")
+
+	t.Run("named route", func(t *testing.T) {
+		u, err := router.URL("event", "id", "123")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "/v1/events/123", u.String())
+		}
+	})
+	t.Run("unknown route", func(t *testing.T) {
+		_, err := router.URL("unknown")
+		if assert.Error(t, err) {
+			assert.Equal(t, "Route not found: name=unknown", err.Error())
+		}
+	})
+}
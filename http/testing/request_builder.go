@@ -1,13 +1,9 @@
 package testing
 
 import (
-	"bytes"
-	core_http "net/http"
-	"net/http/httptest"
-
-	"github.com/gorilla/mux"
 	"github.com/gotech-labs/api"
 	"github.com/gotech-labs/api/http"
+	apitest "github.com/gotech-labs/api/testing"
 )
 
 type RequestBuilder struct {
@@ -17,28 +13,14 @@ type RequestBuilder struct {
 	Headers     map[string][]string
 	PathParams  map[string]string
 	QueryParams map[string]string
+	// FormValues is sent as application/x-www-form-urlencoded body, or as multipart/form-data with Files.
+	FormValues map[string][]string
+	Files      []File
 }
 
+// File is a file uploaded by the multipart/form-data request.
+type File = apitest.File
+
 func (rb RequestBuilder) Build() api.Request {
-	req := httptest.NewRequest(
-		rb.Method,
-		rb.Path,
-		bytes.NewBuffer(rb.Body),
-	)
-	if len(rb.PathParams) > 0 {
-		req = mux.SetURLVars(req, rb.PathParams)
-	}
-	if len(rb.Headers) > 0 {
-		req.Header = core_http.Header(rb.Headers)
-	}
-	if len(rb.QueryParams) > 0 {
-		values := req.URL.Query()
-		for k, v := range rb.QueryParams {
-			values.Add(k, v)
-		}
-		req.URL.RawQuery = values.Encode()
-	}
-	req.RemoteAddr = "127.0.0.1:80"
-	req.URL.Host = "localhost"
-	return http.NewRequest(req)
+	return http.NewRequest(apitest.RequestBuilder(rb).Build())
 }
@@ -0,0 +1,303 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gotech-labs/api"
+)
+
+// serveWebSocket hijacks the connection, completes the handshake and runs the WebSocket handler.
+func serveWebSocket(w http.ResponseWriter, r *http.Request, resp api.UpgradeResponse) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		writeResponse(w, r, api.InternalServerError(api.WebSocketError.New(
+			"Failed to upgrade connection: response writer does not support hijacking")))
+		return
+	}
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		writeResponse(w, r, api.InternalServerError(api.WebSocketError.Wrapf(err,
+			"Failed to upgrade connection: error=%v", err.Error())))
+		return
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n", http.StatusSwitchingProtocols, http.StatusText(http.StatusSwitchingProtocols))
+	if err := resp.HeaderValues().Write(rw); err != nil {
+		netConn.Close()
+		return
+	}
+	if _, err := rw.WriteString("\r\n"); err != nil {
+		netConn.Close()
+		return
+	}
+	if err := rw.Flush(); err != nil {
+		netConn.Close()
+		return
+	}
+
+	// the request context is no longer canceled by the server after hijacking
+	parent := resp.Context()
+	if parent == nil {
+		parent = r.Context()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	conn := newWebSocketConn(netConn, rw.Reader, resp.HeaderValues().Get(headerSecWebSocketProtocol), cancel)
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close(api.CloseGoingAway, "")
+	}()
+	defer cancel()
+	defer func() {
+		// the recovery middleware has already returned, so the panic is passed on to
+		// the http server, which logs it to its ErrorLog, after closing the connection
+		if r := recover(); r != nil {
+			_ = conn.Close(api.CloseInternalServerError, "")
+			panic(r)
+		}
+	}()
+	if err := resp.WebSocketHandler()(ctx, conn); err != nil {
+		_ = conn.Close(api.CloseInternalServerError, "")
+		return
+	}
+	_ = conn.Close(api.CloseNormalClosure, "")
+}
+
+func newWebSocketConn(netConn net.Conn, br *bufio.Reader, subprotocol string, cancel context.CancelFunc) *webSocketConn {
+	return &webSocketConn{
+		conn:           netConn,
+		reader:         br,
+		subprotocol:    subprotocol,
+		cancel:         cancel,
+		maxMessageSize: defaultMaxMessageSize,
+	}
+}
+
+// webSocketConn is the server side WebSocket connection (RFC 6455).
+type webSocketConn struct {
+	conn           net.Conn
+	reader         *bufio.Reader
+	subprotocol    string
+	cancel         context.CancelFunc
+	maxMessageSize int64
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closed    bool
+}
+
+func (c *webSocketConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+func (c *webSocketConn) ReadMessage() (api.MessageType, []byte, error) {
+	var (
+		messageType api.MessageType
+		message     []byte
+	)
+	for {
+		frame, err := c.readFrame()
+		if err != nil {
+			c.abort()
+			return 0, nil, err
+		}
+		switch frame.opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, frame.payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			closeErr := parseClosePayload(frame.payload)
+			_ = c.Close(closeErr.Code, "")
+			return 0, nil, closeErr
+		case opText, opBinary:
+			if messageType != 0 {
+				return 0, nil, c.fail(api.CloseProtocolError, "unexpected data frame in fragmented message")
+			}
+			messageType = api.MessageType(frame.opcode)
+		case opContinuation:
+			if messageType == 0 {
+				return 0, nil, c.fail(api.CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(api.CloseProtocolError, fmt.Sprintf("unknown opcode=%v", frame.opcode))
+		}
+		if int64(len(message)+len(frame.payload)) > c.maxMessageSize {
+			return 0, nil, c.fail(api.CloseMessageTooBig, "message too big")
+		}
+		message = append(message, frame.payload...)
+		if frame.fin {
+			break
+		}
+	}
+	if messageType == api.TextMessage && !utf8.Valid(message) {
+		return 0, nil, c.fail(api.CloseInvalidPayload, "invalid utf-8 text")
+	}
+	return messageType, message, nil
+}
+
+func (c *webSocketConn) WriteMessage(messageType api.MessageType, data []byte) error {
+	if messageType != api.TextMessage && messageType != api.BinaryMessage {
+		return api.WebSocketError.New(fmt.Sprintf("Invalid message type: type=%v", messageType))
+	}
+	return c.writeFrame(byte(messageType), data)
+}
+
+func (c *webSocketConn) Ping(data []byte) error {
+	return c.writeFrame(opPing, data)
+}
+
+// Close sends the close frame and closes the underlying connection. It is safe to call it more than once.
+func (c *webSocketConn) Close(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		var payload []byte
+		if code != api.CloseNoStatusReceived {
+			payload = make([]byte, 2, 2+len(reason))
+			binary.BigEndian.PutUint16(payload, uint16(code))
+			payload = append(payload, reason...)
+		}
+		err = c.writeFrame(opClose, payload)
+		c.writeMu.Lock()
+		c.closed = true
+		c.writeMu.Unlock()
+		c.conn.Close()
+		c.cancel()
+	})
+	return err
+}
+
+// fail closes the connection with the code and returns the error describing the failure.
+func (c *webSocketConn) fail(code int, reason string) error {
+	_ = c.Close(code, reason)
+	return &api.CloseError{Code: code, Reason: reason}
+}
+
+// abort closes the connection without the closing handshake, e.g. when the client disconnected.
+func (c *webSocketConn) abort() {
+	c.closeOnce.Do(func() {
+		c.writeMu.Lock()
+		c.closed = true
+		c.writeMu.Unlock()
+		c.conn.Close()
+		c.cancel()
+	})
+}
+
+type wsFrame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+func (c *webSocketConn) readFrame() (*wsFrame, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return nil, &api.CloseError{Code: api.CloseAbnormalClosure, Reason: err.Error()}
+	}
+	frame := &wsFrame{
+		fin:    header[0]&0x80 != 0,
+		opcode: header[0] & 0x0f,
+	}
+	if header[0]&0x70 != 0 {
+		return nil, c.fail(api.CloseProtocolError, "reserved bits are set")
+	}
+	masked := header[1]&0x80 != 0
+	if !masked {
+		return nil, c.fail(api.CloseProtocolError, "client frame is not masked")
+	}
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return nil, &api.CloseError{Code: api.CloseAbnormalClosure, Reason: err.Error()}
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return nil, &api.CloseError{Code: api.CloseAbnormalClosure, Reason: err.Error()}
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if frame.opcode >= opClose && (length > 125 || !frame.fin) {
+		return nil, c.fail(api.CloseProtocolError, "invalid control frame")
+	}
+	if length < 0 || length > c.maxMessageSize {
+		return nil, c.fail(api.CloseMessageTooBig, "message too big")
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return nil, &api.CloseError{Code: api.CloseAbnormalClosure, Reason: err.Error()}
+	}
+	frame.payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, frame.payload); err != nil {
+		return nil, &api.CloseError{Code: api.CloseAbnormalClosure, Reason: err.Error()}
+	}
+	for i := range frame.payload {
+		frame.payload[i] ^= mask[i%4]
+	}
+	return frame, nil
+}
+
+func (c *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return &api.CloseError{Code: api.CloseAbnormalClosure, Reason: "connection closed"}
+	}
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseClosePayload(payload []byte) *api.CloseError {
+	if len(payload) < 2 {
+		return &api.CloseError{Code: api.CloseNoStatusReceived}
+	}
+	return &api.CloseError{
+		Code:   int(binary.BigEndian.Uint16(payload)),
+		Reason: string(payload[2:]),
+	}
+}
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+
+	defaultMaxMessageSize = 32 << 20
+	writeTimeout          = 10 * time.Second
+
+	headerSecWebSocketProtocol = "Sec-WebSocket-Protocol"
+)
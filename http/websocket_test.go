@@ -0,0 +1,257 @@
+package http_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	. "github.com/gotech-labs/api/http"
+	"github.com/gotech-labs/api/middleware/accesslog"
+	"github.com/gotech-labs/api/middleware/recovery"
+	"github.com/gotech-labs/api/middleware/requestid"
+)
+
+func TestWebSocket(t *testing.T) {
+	var (
+		closed  = make(chan error, 1)
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			return api.WebSocket(ctx, req, func(ctx context.Context, conn api.WebSocketConn) error {
+				for {
+					messageType, data, err := conn.ReadMessage()
+					if err != nil {
+						<-ctx.Done()
+						closed <- err
+						return nil
+					}
+					if string(data) == "ping me" {
+						if err := conn.Ping([]byte("server")); err != nil {
+							return err
+						}
+					}
+					if err := conn.WriteMessage(messageType, data); err != nil {
+						return err
+					}
+				}
+			})
+		}
+		logs   = new(bytes.Buffer)
+		server = httptest.NewServer(NewHandler(handler,
+			accesslog.New(logs).WithSkipPath().Middleware(),
+			recovery.New(io.Discard).Middleware(),
+		))
+	)
+	defer server.Close()
+
+	client := dialWebSocket(t, server.URL)
+	defer client.conn.Close()
+
+	// echo text and binary messages
+	client.writeFrame(0x1, []byte("hello"), true)
+	opcode, payload := client.readFrame()
+	assert.Equal(t, byte(0x1), opcode)
+	assert.Equal(t, "hello", string(payload))
+
+	client.writeFrame(0x2, []byte{0x01, 0x02}, true)
+	opcode, payload = client.readFrame()
+	assert.Equal(t, byte(0x2), opcode)
+	assert.Equal(t, []byte{0x01, 0x02}, payload)
+
+	// fragmented message
+	client.writeFrame(0x1, []byte("frag"), false)
+	client.writeFrame(0x0, []byte("mented"), true)
+	_, payload = client.readFrame()
+	assert.Equal(t, "fragmented", string(payload))
+
+	// ping from client is answered with pong
+	client.writeFrame(0x9, []byte("client"), true)
+	opcode, payload = client.readFrame()
+	assert.Equal(t, byte(0xa), opcode)
+	assert.Equal(t, "client", string(payload))
+
+	// ping from server
+	client.writeFrame(0x1, []byte("ping me"), true)
+	opcode, payload = client.readFrame()
+	assert.Equal(t, byte(0x9), opcode)
+	assert.Equal(t, "server", string(payload))
+	_, payload = client.readFrame()
+	assert.Equal(t, "ping me", string(payload))
+
+	// close handshake
+	closePayload := make([]byte, 2)
+	binary.BigEndian.PutUint16(closePayload, api.CloseGoingAway)
+	client.writeFrame(0x8, append(closePayload, "bye"...), true)
+	opcode, payload = client.readFrame()
+	assert.Equal(t, byte(0x8), opcode)
+	assert.Equal(t, uint16(api.CloseGoingAway), binary.BigEndian.Uint16(payload))
+
+	select {
+	case err := <-closed:
+		closeErr, ok := err.(*api.CloseError)
+		if assert.True(t, ok) {
+			assert.Equal(t, api.CloseGoingAway, closeErr.Code)
+			assert.Equal(t, "bye", closeErr.Reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler did not observe close")
+	}
+	assert.Contains(t, logs.String(), `"status":101`)
+}
+
+func TestWebSocketHandlerError(t *testing.T) {
+	var (
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			return api.WebSocket(ctx, req, func(ctx context.Context, conn api.WebSocketConn) error {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return err
+				}
+				return io.ErrUnexpectedEOF
+			})
+		}
+		server = httptest.NewServer(NewHandler(handler))
+	)
+	defer server.Close()
+
+	client := dialWebSocket(t, server.URL)
+	defer client.conn.Close()
+
+	client.writeFrame(0x1, []byte("hello"), true)
+	opcode, payload := client.readFrame()
+	assert.Equal(t, byte(0x8), opcode)
+	assert.Equal(t, uint16(api.CloseInternalServerError), binary.BigEndian.Uint16(payload))
+}
+
+func TestWebSocketHandlerContext(t *testing.T) {
+	var (
+		ids     = make(chan string, 1)
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			return api.WebSocket(ctx, req, func(ctx context.Context, conn api.WebSocketConn) error {
+				ids <- requestid.FromContext(ctx)
+				panic("unexpected")
+			})
+		}
+		logs   = new(syncBuffer)
+		server = httptest.NewUnstartedServer(NewHandler(handler,
+			recovery.New(io.Discard).Middleware(),
+			requestid.New().WithGenerator(func() string { return "req-1" }).Middleware(),
+		))
+	)
+	server.Config.ErrorLog = log.New(logs, "", 0)
+	server.Start()
+	defer server.Close()
+
+	client := dialWebSocket(t, server.URL)
+	defer client.conn.Close()
+
+	opcode, payload := client.readFrame()
+	assert.Equal(t, byte(0x8), opcode)
+	assert.Equal(t, uint16(api.CloseInternalServerError), binary.BigEndian.Uint16(payload))
+	assert.Equal(t, "req-1", <-ids)
+	assert.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), "panic serving") && strings.Contains(logs.String(), "unexpected")
+	}, time.Second, 10*time.Millisecond)
+}
+
+// syncBuffer is the buffer written by the http server and read by the test concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWebSocketInvalidHandshake(t *testing.T) {
+	var (
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			return api.WebSocket(ctx, req, func(ctx context.Context, conn api.WebSocketConn) error {
+				return nil
+			})
+		}
+		req = httptest.NewRequest(http.MethodGet, "/ws", nil)
+		rec = httptest.NewRecorder()
+	)
+	NewHandler(handler).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+type webSocketClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	t      *testing.T
+}
+
+func dialWebSocket(t *testing.T, url string) *webSocketClient {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req, _ := http.NewRequest(http.MethodGet, url+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	return &webSocketClient{conn: conn, reader: reader, t: t}
+}
+
+func (c *webSocketClient) writeFrame(opcode byte, payload []byte, fin bool) {
+	var (
+		header = []byte{opcode, 0x80 | byte(len(payload))}
+		mask   = []byte{0x12, 0x34, 0x56, 0x78}
+		masked = make([]byte, len(payload))
+	)
+	if fin {
+		header[0] |= 0x80
+	}
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+	frame := append(append(header, mask...), masked...)
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *webSocketClient) readFrame() (byte, []byte) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		c.t.Fatal(err)
+	}
+	payload := make([]byte, header[1]&0x7f)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	return header[0] & 0x0f, payload
+}
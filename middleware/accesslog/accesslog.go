@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/gotech-labs/api"
+	"github.com/gotech-labs/api/middleware/logger"
+	"github.com/gotech-labs/api/middleware/requestid"
 	"github.com/gotech-labs/core/log"
 	"github.com/gotech-labs/core/system"
 	"github.com/rs/zerolog"
@@ -18,6 +20,7 @@ func New(writer io.Writer) *accessLog {
 		loggingFilter:        func(_ string) bool { return false },
 		loggingReqBodyFilter: func(api.Request) bool { return false },
 		logger:               log.New(writer),
+		requestIDHeader:      requestid.HeaderName,
 	}
 }
 
@@ -25,6 +28,7 @@ type accessLog struct {
 	loggingFilter        func(string) bool
 	loggingReqBodyFilter func(api.Request) bool
 	logger               *log.Logger
+	requestIDHeader      string
 }
 
 func (mw *accessLog) Middleware() api.MiddlewareFunc {
@@ -38,7 +42,7 @@ func (mw *accessLog) Middleware() api.MiddlewareFunc {
 					body = req.Body()
 				}
 				defer func(begin time.Time) {
-					evt := mw.logEvent(resp.Status()).
+					evt := mw.logEvent(ctx, resp.Status()).
 						Int("status", resp.Status()).
 						Str("method", req.Method()).
 						Str("path", req.Path()).
@@ -51,6 +55,9 @@ func (mw *accessLog) Middleware() api.MiddlewareFunc {
 						Dur("latency", system.CurrentTime().Sub(begin)).
 						Str("target", req.Host()).
 						Str("server", host)
+					if id := mw.requestID(ctx, resp); id != "" {
+						evt = evt.Str("request_id", id)
+					}
 					if len(body) > 0 {
 						evt = evt.RawJSON("body", body)
 					}
@@ -81,15 +88,32 @@ func (mw *accessLog) WithLoggingReqBodyFilter(filter func(api.Request) bool) *ac
 	return mw
 }
 
-func (mw *accessLog) logEvent(status int) *zerolog.Event {
+// WithRequestIDHeader changes the response header of the request id set by the requestid middleware
+// running inside of accesslog, e.g. when it is changed by requestid WithHeader.
+func (mw *accessLog) WithRequestIDHeader(header string) *accessLog {
+	mw.requestIDHeader = header
+	return mw
+}
+
+func (mw *accessLog) logEvent(ctx context.Context, status int) *zerolog.Event {
+	// share the fields of the request logger, except for the ones logged below
+	l := logger.With(ctx, mw.logger, "method", "path", "request_id")
 	switch {
 	case status >= http.StatusBadRequest && status < http.StatusInternalServerError:
-		return mw.logger.Warn()
+		return l.Warn()
 	case status >= http.StatusInternalServerError:
-		return mw.logger.Error()
+		return l.Error()
 	default:
-		return mw.logger.Info()
+		return l.Info()
+	}
+}
+
+// requestID returns the request id set by the requestid middleware, which may run inside of accesslog.
+func (mw *accessLog) requestID(ctx context.Context, resp api.Response) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return id
 	}
+	return requestid.FromResponse(resp, mw.requestIDHeader)
 }
 
 var (
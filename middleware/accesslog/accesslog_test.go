@@ -3,17 +3,20 @@ package accesslog_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"testing"
 
+	apihttp "github.com/gotech-labs/api/http"
 	apitest "github.com/gotech-labs/api/http/testing"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gotech-labs/api"
 	. "github.com/gotech-labs/api/middleware/accesslog"
+	"github.com/gotech-labs/api/middleware/requestid"
 	"github.com/gotech-labs/core/system"
 )
 
@@ -150,7 +153,14 @@ func TestAccessLog(t *testing.T) {
 				return true
 			}
 			handler = func(ctx context.Context, req api.Request) api.Response {
-				return api.OK(map[string]string{"message": "ok"})
+				// request body can be bound after it has been read for logging
+				input := struct {
+					Keyword string `json:"keyword"`
+				}{}
+				if err := req.Bind(&input); err != nil {
+					return api.BadRequest(err)
+				}
+				return api.OK(map[string]string{"message": input.Keyword})
 			}
 			middleware = New(buf).
 					WithSkipPath(skipPath...).
@@ -290,3 +300,84 @@ func TestAccessLog(t *testing.T) {
 		assert.JSONEq(t, expected, buf.String())
 	})
 }
+
+func TestAccessLogClientIP(t *testing.T) {
+	proxies, err := apihttp.NewTrustedProxies("127.0.0.0/8")
+	if !assert.NoError(t, err) {
+		return
+	}
+	apihttp.DefaultTrustedProxies = proxies
+	defer func() { apihttp.DefaultTrustedProxies = &apihttp.TrustedProxies{} }()
+
+	system.RunTest(t, "client ip behind trusted proxy", func(t *testing.T) {
+		var (
+			req = apitest.RequestBuilder{
+				Method: http.MethodGet,
+				Path:   "/search",
+				Headers: map[string][]string{
+					"X-Forwarded-For": {"198.51.100.1, 203.0.113.1"},
+				},
+			}.Build()
+			buf     = bytes.NewBuffer(nil)
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				return api.NoContent()
+			}
+		)
+		New(buf).WithSkipPath().Middleware()(handler)(context.Background(), req)
+
+		log := map[string]interface{}{}
+		if assert.NoError(t, json.Unmarshal(buf.Bytes(), &log)) {
+			assert.Equal(t, "203.0.113.1", log["client_ip"])
+		}
+	})
+}
+
+func TestAccessLogRequestID(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		chain func(buf *bytes.Buffer) *api.Chain
+	}{
+		{
+			name: "requestid outside of accesslog",
+			chain: func(buf *bytes.Buffer) *api.Chain {
+				return api.NewChain(requestid.New().Middleware(), New(buf).WithSkipPath().Middleware())
+			},
+		},
+		{
+			name: "requestid inside of accesslog",
+			chain: func(buf *bytes.Buffer) *api.Chain {
+				return api.NewChain(New(buf).WithSkipPath().Middleware(), requestid.New().Middleware())
+			},
+		},
+		{
+			name: "requestid with custom header inside of accesslog",
+			chain: func(buf *bytes.Buffer) *api.Chain {
+				return api.NewChain(New(buf).WithSkipPath().WithRequestIDHeader("X-Correlation-Id").Middleware(),
+					requestid.New().WithHeader("X-Correlation-Id").Middleware())
+			},
+		},
+	} {
+		system.RunTest(t, tc.name, func(t *testing.T) {
+			var (
+				req = apitest.RequestBuilder{
+					Method: http.MethodGet,
+					Path:   "/search",
+					Headers: map[string][]string{
+						"X-Request-Id":     {"req-12345"},
+						"X-Correlation-Id": {"req-12345"},
+					},
+				}.Build()
+				buf     = bytes.NewBuffer(nil)
+				handler = func(ctx context.Context, req api.Request) api.Response {
+					return api.NoContent()
+				}
+			)
+			tc.chain(buf).Then(handler)(context.Background(), req)
+
+			log := map[string]interface{}{}
+			if assert.NoError(t, json.Unmarshal(buf.Bytes(), &log)) {
+				assert.Equal(t, "req-12345", log["request_id"])
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package cors
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotech-labs/api"
+)
+
+// New returns the CORS middleware allowing the origins. An origin is either exact, e.g. "https://example.com",
+// a wildcard subdomain, e.g. "https://*.example.com", or "*" for any origin.
+func New(origins ...string) *cors {
+	mw := &cors{
+		methods: []string{
+			http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete,
+		},
+		headers: []string{"Accept", "Authorization", "Content-Type", "X-Request-Id"},
+	}
+	for _, origin := range origins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			mw.allowAll = true
+		case strings.Contains(origin, "://*."):
+			i := strings.Index(origin, "*")
+			mw.wildcards = append(mw.wildcards, wildcard{prefix: origin[:i], suffix: origin[i+1:]})
+		default:
+			mw.origins = append(mw.origins, origin)
+		}
+	}
+	return mw
+}
+
+type cors struct {
+	allowAll         bool
+	origins          []string
+	wildcards        []wildcard
+	patterns         []*regexp.Regexp
+	methods          []string
+	headers          []string
+	exposeHeaders    []string
+	allowCredentials bool
+	maxAge           time.Duration
+}
+
+func (mw *cors) Middleware() api.MiddlewareFunc {
+	return func(next api.HandlerFunc) api.HandlerFunc {
+		return func(ctx context.Context, req api.Request) api.Response {
+			origin := firstHeader(req, headerOrigin)
+			if req.Method() == http.MethodOptions && origin != "" && firstHeader(req, headerRequestMethod) != "" {
+				// answer the preflight request without calling the handler
+				return mw.preflight(req, origin)
+			}
+			resp := next(ctx, req)
+			if resp == nil {
+				return resp
+			}
+			mw.addVary(resp, headerOrigin)
+			if origin == "" || !mw.allowOrigin(origin) {
+				return resp
+			}
+			resp.WithHeader(headerAllowOrigin, mw.allowOriginValue(origin))
+			if mw.allowCredentials {
+				resp.WithHeader(headerAllowCredentials, "true")
+			}
+			if len(mw.exposeHeaders) > 0 {
+				resp.WithHeader(headerExposeHeaders, strings.Join(mw.exposeHeaders, ", "))
+			}
+			return resp
+		}
+	}
+}
+
+// WithOriginPatterns allows the origins matching the regular expressions, e.g. `^https://[a-z]+\.example\.com$`.
+func (mw *cors) WithOriginPatterns(patterns ...string) *cors {
+	for _, pattern := range patterns {
+		mw.patterns = append(mw.patterns, regexp.MustCompile(pattern))
+	}
+	return mw
+}
+
+// WithMethods replaces the methods allowed by preflight requests.
+func (mw *cors) WithMethods(methods ...string) *cors {
+	mw.methods = methods
+	return mw
+}
+
+// WithHeaders replaces the request headers allowed by preflight requests.
+func (mw *cors) WithHeaders(headers ...string) *cors {
+	mw.headers = headers
+	return mw
+}
+
+// WithExposeHeaders sets the response headers exposed to the browser clients.
+func (mw *cors) WithExposeHeaders(headers ...string) *cors {
+	mw.exposeHeaders = headers
+	return mw
+}
+
+// WithCredentials allows the requests with credentials such as cookies.
+// It panics when any origin is allowed with "*", since every site could then send credentialed requests.
+func (mw *cors) WithCredentials() *cors {
+	if mw.allowAll {
+		panic("Invalid cors configuration: credentials can not be allowed for any origin")
+	}
+	mw.allowCredentials = true
+	return mw
+}
+
+// WithMaxAge sets how long the browser caches the result of preflight requests.
+func (mw *cors) WithMaxAge(maxAge time.Duration) *cors {
+	mw.maxAge = maxAge
+	return mw
+}
+
+func (mw *cors) preflight(req api.Request, origin string) api.Response {
+	resp := api.NoContent()
+	mw.addVary(resp, headerOrigin)
+	mw.addVary(resp, headerRequestMethod)
+	mw.addVary(resp, headerRequestHeaders)
+	if !mw.allowOrigin(origin) || !mw.allowMethod(firstHeader(req, headerRequestMethod)) {
+		return resp
+	}
+	requested := requestedHeaders(req)
+	for _, header := range requested {
+		if !containsFold(mw.headers, header) {
+			return resp
+		}
+	}
+	resp.WithHeader(headerAllowOrigin, mw.allowOriginValue(origin))
+	resp.WithHeader(headerAllowMethods, strings.Join(mw.methods, ", "))
+	if len(requested) > 0 {
+		resp.WithHeader(headerAllowHeaders, strings.Join(requested, ", "))
+	}
+	if mw.allowCredentials {
+		resp.WithHeader(headerAllowCredentials, "true")
+	}
+	if mw.maxAge > 0 {
+		resp.WithHeader(headerMaxAge, strconv.Itoa(int(mw.maxAge/time.Second)))
+	}
+	return resp
+}
+
+func (mw *cors) allowOrigin(origin string) bool {
+	if mw.allowAll {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	for _, o := range mw.origins {
+		if o == lower {
+			return true
+		}
+	}
+	for _, w := range mw.wildcards {
+		if w.match(lower) {
+			return true
+		}
+	}
+	for _, p := range mw.patterns {
+		if p.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mw *cors) allowOriginValue(origin string) string {
+	if mw.allowAll {
+		return "*"
+	}
+	return origin
+}
+
+func (mw *cors) allowMethod(method string) bool {
+	for _, m := range mw.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary adds the header to Vary unless the response is the same for every origin.
+func (mw *cors) addVary(resp api.Response, header string) {
+	if mw.allowAll && header == headerOrigin {
+		return
+	}
+	for _, value := range resp.HeaderValues().Values(headerVary) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v == "*" || strings.EqualFold(v, header) {
+				return
+			}
+		}
+	}
+	resp.AddHeader(headerVary, header)
+}
+
+// wildcard matches the origins of any subdomain, e.g. "https://*.example.com".
+type wildcard struct {
+	prefix string
+	suffix string
+}
+
+func (w wildcard) match(origin string) bool {
+	if len(origin) <= len(w.prefix)+len(w.suffix) ||
+		!strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+		return false
+	}
+	sub := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+	return !strings.ContainsAny(sub, "/:@")
+}
+
+func requestedHeaders(req api.Request) []string {
+	var headers []string
+	for _, value := range req.Header(headerRequestHeaders) {
+		for _, header := range strings.Split(value, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, header)
+			}
+		}
+	}
+	return headers
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstHeader(req api.Request, key string) string {
+	if values := req.Header(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+const (
+	headerOrigin           = "Origin"
+	headerVary             = "Vary"
+	headerRequestMethod    = "Access-Control-Request-Method"
+	headerRequestHeaders   = "Access-Control-Request-Headers"
+	headerAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAllowMethods     = "Access-Control-Allow-Methods"
+	headerAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAllowCredentials = "Access-Control-Allow-Credentials"
+	headerExposeHeaders    = "Access-Control-Expose-Headers"
+	headerMaxAge           = "Access-Control-Max-Age"
+)
@@ -0,0 +1,247 @@
+package cors_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	apihttp "github.com/gotech-labs/api/http"
+	apitest "github.com/gotech-labs/api/http/testing"
+	. "github.com/gotech-labs/api/middleware/cors"
+)
+
+func TestCORS(t *testing.T) {
+	var (
+		called  bool
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			called = true
+			return api.OK("ok").AddHeader("Vary", "Accept")
+		}
+		middleware = New("https://example.com", "https://*.example.org").
+				WithOriginPatterns(`^https://[a-z]+\.example\.net$`).
+				WithHeaders("Content-Type", "Authorization").
+				WithExposeHeaders("X-Request-Id").
+				WithCredentials().
+				WithMaxAge(10 * time.Minute).
+				Middleware()
+	)
+	for _, test := range []struct {
+		name     string
+		method   string
+		headers  map[string][]string
+		called   bool
+		status   int
+		expected map[string]string
+		vary     []string
+	}{
+		{
+			name:   "same origin request",
+			method: http.MethodGet,
+			called: true,
+			status: http.StatusOK,
+			vary:   []string{"Accept", "Origin"},
+		},
+		{
+			name:    "exact origin",
+			method:  http.MethodGet,
+			headers: map[string][]string{"Origin": {"https://example.com"}},
+			called:  true,
+			status:  http.StatusOK,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-Id",
+			},
+			vary: []string{"Accept", "Origin"},
+		},
+		{
+			name:    "wildcard subdomain origin",
+			method:  http.MethodPost,
+			headers: map[string][]string{"Origin": {"https://api.example.org"}},
+			called:  true,
+			status:  http.StatusOK,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://api.example.org",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-Id",
+			},
+			vary: []string{"Accept", "Origin"},
+		},
+		{
+			name:    "wildcard does not match the parent domain",
+			method:  http.MethodGet,
+			headers: map[string][]string{"Origin": {"https://example.org"}},
+			called:  true,
+			status:  http.StatusOK,
+			vary:    []string{"Accept", "Origin"},
+		},
+		{
+			name:    "regex origin",
+			method:  http.MethodGet,
+			headers: map[string][]string{"Origin": {"https://app.example.net"}},
+			called:  true,
+			status:  http.StatusOK,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.net",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-Id",
+			},
+			vary: []string{"Accept", "Origin"},
+		},
+		{
+			name:    "disallowed origin",
+			method:  http.MethodGet,
+			headers: map[string][]string{"Origin": {"https://evil.com"}},
+			called:  true,
+			status:  http.StatusOK,
+			vary:    []string{"Accept", "Origin"},
+		},
+		{
+			name:   "preflight request",
+			method: http.MethodOptions,
+			headers: map[string][]string{
+				"Origin":                         {"https://example.com"},
+				"Access-Control-Request-Method":  {"PUT"},
+				"Access-Control-Request-Headers": {"content-type, authorization"},
+			},
+			status: http.StatusNoContent,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://example.com",
+				"Access-Control-Allow-Methods":     "GET, HEAD, POST, PUT, PATCH, DELETE",
+				"Access-Control-Allow-Headers":     "content-type, authorization",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			},
+			vary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:   "preflight request with disallowed header",
+			method: http.MethodOptions,
+			headers: map[string][]string{
+				"Origin":                         {"https://example.com"},
+				"Access-Control-Request-Method":  {"PUT"},
+				"Access-Control-Request-Headers": {"X-Unknown"},
+			},
+			status: http.StatusNoContent,
+			vary:   []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:   "preflight request with disallowed method",
+			method: http.MethodOptions,
+			headers: map[string][]string{
+				"Origin":                        {"https://example.com"},
+				"Access-Control-Request-Method": {"TRACE"},
+			},
+			status: http.StatusNoContent,
+			vary:   []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			called = false
+			req := apitest.RequestBuilder{
+				Method:  test.method,
+				Path:    "/events",
+				Headers: test.headers,
+			}.Build()
+			resp := middleware(handler)(context.Background(), req)
+
+			assert.Equal(t, test.called, called)
+			assert.Equal(t, test.status, resp.Status())
+			for _, key := range []string{
+				"Access-Control-Allow-Origin",
+				"Access-Control-Allow-Methods",
+				"Access-Control-Allow-Headers",
+				"Access-Control-Allow-Credentials",
+				"Access-Control-Expose-Headers",
+				"Access-Control-Max-Age",
+			} {
+				assert.Equal(t, test.expected[key], resp.HeaderValues().Get(key), key)
+			}
+			assert.Equal(t, test.vary, resp.HeaderValues().Values("Vary"))
+		})
+	}
+}
+
+func TestCORSAllowAll(t *testing.T) {
+	var (
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			return api.OK("ok")
+		}
+		req = apitest.RequestBuilder{
+			Method:  http.MethodGet,
+			Path:    "/events",
+			Headers: map[string][]string{"Origin": {"https://any.com"}},
+		}.Build()
+	)
+
+	t.Run("without credentials", func(t *testing.T) {
+		resp := New("*").Middleware()(handler)(context.Background(), req)
+		assert.Equal(t, "*", resp.HeaderValues().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, resp.HeaderValues().Values("Vary"))
+	})
+
+	t.Run("with credentials", func(t *testing.T) {
+		assert.PanicsWithValue(t, "Invalid cors configuration: credentials can not be allowed for any origin", func() {
+			New("*").WithCredentials()
+		})
+	})
+}
+
+func TestCORSRouterPreflight(t *testing.T) {
+	created := func(ctx context.Context, req api.Request) api.Response {
+		return api.Created("created")
+	}
+	for _, test := range []struct {
+		name   string
+		router func() *apihttp.Router
+		path   string
+	}{
+		{
+			name: "router middleware",
+			router: func() *apihttp.Router {
+				router := apihttp.NewRouter(New("https://example.com").Middleware())
+				router.POST("/events", created)
+				return router
+			},
+			path: "/events",
+		},
+		{
+			name: "use",
+			router: func() *apihttp.Router {
+				router := apihttp.NewRouter()
+				router.Use(New("https://example.com").Middleware())
+				router.POST("/events", created)
+				return router
+			},
+			path: "/events",
+		},
+		{
+			name: "group",
+			router: func() *apihttp.Router {
+				router := apihttp.NewRouter()
+				router.Group("/api", New("https://example.com").Middleware()).
+					POST("/events/{id}", created)
+				return router
+			},
+			path: "/api/events/1",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				rec = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodOptions, test.path, nil)
+			)
+			req.Header.Set("Origin", "https://example.com")
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			test.router().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
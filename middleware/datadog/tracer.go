@@ -11,6 +11,7 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
 	"github.com/gotech-labs/api"
+	"github.com/gotech-labs/api/middleware/requestid"
 	"github.com/gotech-labs/core/log"
 )
 
@@ -26,6 +27,7 @@ func New(serviceName, env string) *datadog {
 		resourceNameFunc: func(req api.Request) string {
 			return fmt.Sprintf("%s %s", req.Method(), req.Path())
 		},
+		requestIDHeader: requestid.HeaderName,
 	}
 }
 
@@ -34,6 +36,7 @@ type datadog struct {
 	env              string
 	tracerOpts       []tracer.StartOption
 	resourceNameFunc func(api.Request) string
+	requestIDHeader  string
 }
 
 func (mw *datadog) Middleware() api.MiddlewareFunc {
@@ -63,6 +66,11 @@ func (mw *datadog) Middleware() api.MiddlewareFunc {
 			defer func() {
 				status := resp.Status()
 				span.SetTag(tags.Status, status)
+				if id := requestid.FromContext(ctx); id != "" {
+					span.SetTag(tags.RequestID, id)
+				} else if id := requestid.FromResponse(resp, mw.requestIDHeader); id != "" {
+					span.SetTag(tags.RequestID, id)
+				}
 				if status >= 400 {
 					span.SetTag(tags.Error, fmt.Sprintf("%d %s", status, http.StatusText(status)))
 				}
@@ -74,6 +82,13 @@ func (mw *datadog) Middleware() api.MiddlewareFunc {
 	}
 }
 
+// WithRequestIDHeader changes the response header of the request id set by the requestid middleware
+// running inside of datadog, e.g. when it is changed by requestid WithHeader.
+func (mw *datadog) WithRequestIDHeader(header string) *datadog {
+	mw.requestIDHeader = header
+	return mw
+}
+
 func (mw *datadog) WithEnabledRuntimeMetrics() *datadog {
 	mw.tracerOpts = append(mw.tracerOpts, tracer.WithRuntimeMetrics())
 	return mw
@@ -96,12 +111,14 @@ var tags = struct {
 	URL       string
 	Status    string
 	Error     string
+	RequestID string
 }{
 	Operation: "http.request",
 	Method:    ext.HTTPMethod,
 	URL:       ext.HTTPURL,
 	Status:    ext.HTTPCode,
 	Error:     ext.Error,
+	RequestID: "request_id",
 }
 
 type datadogTraceLogger struct {
@@ -13,6 +13,7 @@ import (
 	"github.com/gotech-labs/api"
 	apitest "github.com/gotech-labs/api/http/testing"
 	. "github.com/gotech-labs/api/middleware/datadog"
+	"github.com/gotech-labs/api/middleware/requestid"
 )
 
 func TestHealth(t *testing.T) {
@@ -80,3 +81,43 @@ func TestHealth(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestIDTag(t *testing.T) {
+	var (
+		req = apitest.RequestBuilder{
+			Method:  http.MethodGet,
+			Path:    "/health",
+			Headers: map[string][]string{"X-Request-Id": {"req-12345"}},
+		}.Build()
+		handler = func(ctx context.Context, req api.Request) api.Response {
+			return api.OK("ok")
+		}
+		middleware = New("api test", "test").Middleware()
+	)
+	defer StopTracer()
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// the requestid middleware runs inside of the tracer
+	middleware(requestid.New().Middleware()(handler))(context.Background(), req)
+
+	spans := mt.FinishedSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "req-12345", spans[0].Tag("request_id"))
+	}
+
+	mt.Reset()
+	req = apitest.RequestBuilder{
+		Method:  http.MethodGet,
+		Path:    "/health",
+		Headers: map[string][]string{"X-Correlation-Id": {"corr-1"}},
+	}.Build()
+	middleware = New("api test", "test").WithRequestIDHeader("X-Correlation-Id").Middleware()
+	middleware(requestid.New().WithHeader("X-Correlation-Id").Middleware()(handler))(context.Background(), req)
+
+	spans = mt.FinishedSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "corr-1", spans[0].Tag("request_id"))
+	}
+}
@@ -2,26 +2,33 @@ package health
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/gotech-labs/api"
+	"github.com/gotech-labs/core/errors"
 )
 
 func New(path string, body interface{}) *health {
 	return &health{
-		path: path,
-		body: body,
+		path:    path,
+		body:    body,
+		healthy: 1,
 	}
 }
 
 type health struct {
-	path string
-	body interface{}
+	path    string
+	body    interface{}
+	healthy int32
 }
 
 func (mw *health) Middleware() api.MiddlewareFunc {
 	return func(next api.HandlerFunc) api.HandlerFunc {
 		return func(ctx context.Context, req api.Request) api.Response {
 			if mw.path == req.Path() {
+				if !mw.Healthy() {
+					return api.ServiceUnavailable(UnhealthyError.New("Service is unhealthy"))
+				}
 				return api.OK(mw.body)
 			}
 			// call next handler function
@@ -29,3 +36,20 @@ func (mw *health) Middleware() api.MiddlewareFunc {
 		}
 	}
 }
+
+// SetHealthy switches the health check response, e.g. to unhealthy while the server is draining.
+func (mw *health) SetHealthy(healthy bool) {
+	var value int32
+	if healthy {
+		value = 1
+	}
+	atomic.StoreInt32(&mw.healthy, value)
+}
+
+func (mw *health) Healthy() bool {
+	return atomic.LoadInt32(&mw.healthy) == 1
+}
+
+var (
+	UnhealthyError = errors.TypedError("unhealthy_error")
+)
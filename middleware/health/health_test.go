@@ -54,4 +54,32 @@ func TestHealth(t *testing.T) {
 		// assert response
 		assert.Equal(t, http.StatusBadRequest, resp.Status())
 	})
+
+	system.RunTest(t, "unhealthy response", func(t *testing.T) {
+		var (
+			rb = apitest.RequestBuilder{
+				Method: http.MethodGet,
+				Path:   "/health",
+				Body:   nil,
+			}
+			req     = rb.Build()
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				return api.OK("ok") // not called
+			}
+			hc         = New("/health", nil)
+			middleware = hc.Middleware()
+		)
+		hc.SetHealthy(false)
+		assert.False(t, hc.Healthy())
+
+		// call middleware function
+		resp := middleware(handler)(context.Background(), req)
+
+		// assert response
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Status())
+
+		hc.SetHealthy(true)
+		resp = middleware(handler)(context.Background(), req)
+		assert.Equal(t, http.StatusOK, resp.Status())
+	})
 }
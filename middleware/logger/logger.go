@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/gotech-labs/api"
+	"github.com/gotech-labs/api/middleware/requestid"
+	"github.com/gotech-labs/core/log"
+)
+
+// New returns the middleware placing the logger enriched with the request fields into the context.
+// It must run inside of the requestid and datadog middlewares to log their ids.
+func New(writer io.Writer) *logger {
+	return &logger{
+		logger: log.New(writer),
+	}
+}
+
+type logger struct {
+	logger *log.Logger
+}
+
+func (mw *logger) Middleware() api.MiddlewareFunc {
+	return func(next api.HandlerFunc) api.HandlerFunc {
+		return func(ctx context.Context, req api.Request) api.Response {
+			var fields []field
+			if id := requestid.FromContext(ctx); id != "" {
+				fields = append(fields, field{key: keyRequestID, value: id})
+			}
+			fields = append(fields,
+				field{key: keyMethod, value: req.Method()},
+				field{key: keyPath, value: req.Path()},
+			)
+			if span, ok := tracer.SpanFromContext(ctx); ok {
+				fields = append(fields,
+					field{key: keyTraceID, value: strconv.FormatUint(span.Context().TraceID(), 10)},
+					field{key: keySpanID, value: strconv.FormatUint(span.Context().SpanID(), 10)},
+				)
+			}
+			entry := &contextEntry{
+				logger: enrich(mw.logger, fields, nil),
+				fields: fields,
+			}
+			// pass the logger through the request context
+			return next(context.WithValue(ctx, contextKey{}, entry), req)
+		}
+	}
+}
+
+// FromContext returns the logger of the request, or the default logger writing to stderr
+// when the context has no logger.
+func FromContext(ctx context.Context) *log.Logger {
+	if entry := entryFromContext(ctx); entry != nil {
+		return entry.logger
+	}
+	return defaultLogger
+}
+
+// With returns the base logger enriched with the fields of the request, except for the excluded keys.
+// Middlewares with their own writers use it to share the fields with handler logs.
+func With(ctx context.Context, base *log.Logger, exclude ...string) *log.Logger {
+	entry := entryFromContext(ctx)
+	if entry == nil {
+		return base
+	}
+	return enrich(base, entry.fields, exclude)
+}
+
+func enrich(base *log.Logger, fields []field, exclude []string) *log.Logger {
+	c := base.With()
+	for _, f := range fields {
+		if !contains(exclude, f.key) {
+			c = c.Str(f.key, f.value)
+		}
+	}
+	return &log.Logger{Logger: c.Logger()}
+}
+
+func entryFromContext(ctx context.Context) *contextEntry {
+	if ctx == nil {
+		return nil
+	}
+	entry, _ := ctx.Value(contextKey{}).(*contextEntry)
+	return entry
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+type contextEntry struct {
+	logger *log.Logger
+	fields []field
+}
+
+type field struct {
+	key   string
+	value string
+}
+
+var (
+	defaultLogger = log.New(os.Stderr)
+)
+
+const (
+	keyRequestID = "request_id"
+	keyMethod    = "method"
+	keyPath      = "path"
+	keyTraceID   = "dd.trace_id"
+	keySpanID    = "dd.span_id"
+)
@@ -0,0 +1,116 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/gotech-labs/api"
+	apitest "github.com/gotech-labs/api/http/testing"
+	. "github.com/gotech-labs/api/middleware/logger"
+	"github.com/gotech-labs/api/middleware/recovery"
+	"github.com/gotech-labs/api/middleware/requestid"
+	"github.com/gotech-labs/core/log"
+	"github.com/gotech-labs/core/system"
+)
+
+func TestLogger(t *testing.T) {
+	system.RunTest(t, "handler log with request fields", func(t *testing.T) {
+		var (
+			req = apitest.RequestBuilder{
+				Method:  http.MethodGet,
+				Path:    "/events",
+				Headers: map[string][]string{"X-Request-Id": {"req-12345"}},
+			}.Build()
+			buf     = bytes.NewBuffer(nil)
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				FromContext(ctx).Info().Msg("handled")
+				return api.NoContent()
+			}
+			chain = api.NewChain(requestid.New().Middleware(), New(buf).Middleware())
+		)
+		chain.Then(handler)(context.Background(), req)
+
+		assert.JSONEq(t, `{
+			"level": "info",
+			"time": "2022-12-24T00:00:00+09:00",
+			"request_id": "req-12345",
+			"method": "GET",
+			"path": "/events",
+			"message": "handled"
+		}`, buf.String())
+	})
+
+	system.RunTest(t, "trace and span ids", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		var (
+			req = apitest.RequestBuilder{
+				Method: http.MethodGet,
+				Path:   "/events",
+			}.Build()
+			buf     = bytes.NewBuffer(nil)
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				FromContext(ctx).Info().Msg("handled")
+				return api.NoContent()
+			}
+			span, ctx = tracer.StartSpanFromContext(context.Background(), "http.request")
+		)
+		New(buf).Middleware()(handler)(ctx, req)
+		span.Finish()
+
+		assert.JSONEq(t, fmt.Sprintf(`{
+			"level": "info",
+			"time": "2022-12-24T00:00:00+09:00",
+			"method": "GET",
+			"path": "/events",
+			"dd.trace_id": "%d",
+			"dd.span_id": "%d",
+			"message": "handled"
+		}`, span.Context().TraceID(), span.Context().SpanID()), buf.String())
+	})
+
+	system.RunTest(t, "panic log with request fields", func(t *testing.T) {
+		var (
+			req = apitest.RequestBuilder{
+				Method:  http.MethodGet,
+				Path:    "/events",
+				Headers: map[string][]string{"X-Request-Id": {"req-12345"}},
+			}.Build()
+			buf     = bytes.NewBuffer(nil)
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				panic("connection error")
+			}
+			chain = api.NewChain(
+				requestid.New().Middleware(),
+				New(bytes.NewBuffer(nil)).Middleware(),
+				recovery.New(buf).Middleware(),
+			)
+		)
+		resp := chain.Then(handler)(context.Background(), req)
+		assert.Equal(t, http.StatusInternalServerError, resp.Status())
+
+		assert.JSONEq(t, `{
+			"level": "error",
+			"time": "2022-12-24T00:00:00+09:00",
+			"request_id": "req-12345",
+			"method": "GET",
+			"path": "/events",
+			"error": "connection error",
+			"message": "panic recovered"
+		}`, buf.String())
+	})
+
+	t.Run("without middleware", func(t *testing.T) {
+		assert.NotNil(t, FromContext(context.Background()))
+		base := log.New(bytes.NewBuffer(nil))
+		assert.Equal(t, base, With(context.Background(), base))
+	})
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Result is the decision of the algorithm for a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the limit is fully available again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, when it is not allowed.
+	RetryAfter time.Duration
+}
+
+// Algorithm decides whether the request of the key is allowed, updating the state in the store.
+type Algorithm interface {
+	Allow(ctx context.Context, store Store, key string, now time.Time) (Result, error)
+}
+
+// TokenBucket allows bursts of up to burst requests, refilled at rate requests per period.
+// It panics when rate, per or burst is not positive.
+func TokenBucket(rate int, per time.Duration, burst int) Algorithm {
+	if rate <= 0 || per <= 0 || burst <= 0 {
+		panic(fmt.Sprintf("Invalid token bucket: rate=%v, per=%v, burst=%v", rate, per, burst))
+	}
+	interval := per / time.Duration(rate)
+	if interval <= 0 {
+		panic(fmt.Sprintf("Invalid token bucket: rate=%v is too high for per=%v", rate, per))
+	}
+	return &tokenBucket{
+		interval: interval,
+		burst:    burst,
+	}
+}
+
+type tokenBucket struct {
+	// interval is the time to refill a token
+	interval time.Duration
+	burst    int
+}
+
+func (a *tokenBucket) Allow(ctx context.Context, store Store, key string, now time.Time) (Result, error) {
+	result := Result{Limit: a.burst}
+	ttl := a.interval * time.Duration(a.burst)
+	err := store.Update(ctx, key, now, ttl, func(state *State) {
+		if state.Timestamp.IsZero() {
+			state.Tokens = float64(a.burst)
+		} else if elapsed := now.Sub(state.Timestamp); elapsed > 0 {
+			state.Tokens = math.Min(float64(a.burst), state.Tokens+float64(elapsed)/float64(a.interval))
+		}
+		state.Timestamp = now
+
+		if state.Tokens >= 1 {
+			state.Tokens--
+			result.Allowed = true
+		} else {
+			result.RetryAfter = time.Duration((1 - state.Tokens) * float64(a.interval))
+		}
+		result.Remaining = int(state.Tokens)
+		result.Reset = time.Duration((float64(a.burst) - state.Tokens) * float64(a.interval))
+	})
+	return result, err
+}
+
+// SlidingWindow allows limit requests in any window, estimated from the counts of the current
+// and the previous fixed windows. It panics when limit or window is not positive.
+func SlidingWindow(limit int, window time.Duration) Algorithm {
+	if limit <= 0 || window <= 0 {
+		panic(fmt.Sprintf("Invalid sliding window: limit=%v, window=%v", limit, window))
+	}
+	return &slidingWindow{
+		limit:  limit,
+		window: window,
+	}
+}
+
+type slidingWindow struct {
+	limit  int
+	window time.Duration
+}
+
+func (a *slidingWindow) Allow(ctx context.Context, store Store, key string, now time.Time) (Result, error) {
+	result := Result{Limit: a.limit}
+	start := now.Truncate(a.window)
+	err := store.Update(ctx, key, now, 2*a.window, func(state *State) {
+		if !state.Timestamp.Equal(start) {
+			if state.Timestamp.Add(a.window).Equal(start) {
+				state.PrevCount = state.Count
+			} else {
+				state.PrevCount = 0
+			}
+			state.Count = 0
+			state.Timestamp = start
+		}
+		var (
+			elapsed  = now.Sub(start)
+			weight   = 1 - float64(elapsed)/float64(a.window)
+			estimate = float64(state.PrevCount)*weight + float64(state.Count)
+		)
+		if estimate+1 <= float64(a.limit) {
+			state.Count++
+			estimate++
+			result.Allowed = true
+		} else {
+			result.RetryAfter = a.retryAfter(state, elapsed)
+		}
+		result.Remaining = int(math.Max(0, math.Floor(float64(a.limit)-estimate)))
+		result.Reset = a.window - elapsed
+	})
+	return result, err
+}
+
+// retryAfter returns the time until the estimate goes below the limit.
+func (a *slidingWindow) retryAfter(state *State, elapsed time.Duration) time.Duration {
+	allowed := float64(a.limit - 1)
+	if state.Count <= a.limit-1 && state.PrevCount > 0 {
+		// the previous window slides out within the current window
+		ratio := 1 - (allowed-float64(state.Count))/float64(state.PrevCount)
+		return time.Duration(ratio*float64(a.window)) - elapsed
+	}
+	// the current window becomes the previous one
+	ratio := 1 - allowed/float64(state.Count)
+	return a.window - elapsed + time.Duration(ratio*float64(a.window))
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gotech-labs/api"
+	"github.com/gotech-labs/api/middleware/logger"
+	"github.com/gotech-labs/core/errors"
+	"github.com/gotech-labs/core/system"
+)
+
+// New returns the middleware limiting the requests of each client IP with the algorithm,
+// e.g. TokenBucket(10, time.Second, 20).
+func New(algorithm Algorithm) *rateLimit {
+	return &rateLimit{
+		algorithm: algorithm,
+		keyFunc:   KeyByClientIP(),
+		store:     NewMemoryStore(),
+	}
+}
+
+type rateLimit struct {
+	algorithm Algorithm
+	keyFunc   func(api.Request) string
+	store     Store
+}
+
+func (mw *rateLimit) Middleware() api.MiddlewareFunc {
+	return func(next api.HandlerFunc) api.HandlerFunc {
+		return func(ctx context.Context, req api.Request) api.Response {
+			key := mw.keyFunc(req)
+			if key == "" {
+				// requests without the key are not limited
+				return next(ctx, req)
+			}
+			result, err := mw.algorithm.Allow(ctx, mw.store, key, system.CurrentTime())
+			if err != nil {
+				// fail open, so that a broken store does not take the service down
+				logger.FromContext(ctx).Error().Err(err).Msg("rate limit store error")
+				return next(ctx, req)
+			}
+			var resp api.Response
+			if result.Allowed {
+				resp = next(ctx, req)
+				if resp == nil {
+					return resp
+				}
+			} else {
+				resp = api.TooManyRequests(LimitExceededError.New(fmt.Sprintf(
+					"Rate limit exceeded: limit=%v", result.Limit))).
+					WithHeader(headerRetryAfter, seconds(result.RetryAfter))
+			}
+			return resp.
+				WithHeader(headerLimit, strconv.Itoa(result.Limit)).
+				WithHeader(headerRemaining, strconv.Itoa(result.Remaining)).
+				WithHeader(headerReset, seconds(result.Reset))
+		}
+	}
+}
+
+// WithKeyFunc changes the key of the limit. Requests with an empty key are not limited.
+func (mw *rateLimit) WithKeyFunc(keyFunc func(api.Request) string) *rateLimit {
+	mw.keyFunc = keyFunc
+	return mw
+}
+
+// WithStore changes the store of the limit states, e.g. to share the limits between servers.
+func (mw *rateLimit) WithStore(store Store) *rateLimit {
+	mw.store = store
+	return mw
+}
+
+// KeyByClientIP limits each client IP.
+func KeyByClientIP() func(api.Request) string {
+	return func(req api.Request) string {
+		return req.ClientIP()
+	}
+}
+
+// KeyByHeader limits each value of the header, e.g. an API key.
+func KeyByHeader(name string) func(api.Request) string {
+	return func(req api.Request) string {
+		if values := req.Header(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+}
+
+// KeyByPathParam limits each value of the path parameter, e.g. a tenant id.
+func KeyByPathParam(name string) func(api.Request) string {
+	return func(req api.Request) string {
+		return req.PathParameter(name)
+	}
+}
+
+// seconds rounds the duration up to whole seconds.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+var (
+	LimitExceededError = errors.TypedError("rate_limit_exceeded_error")
+)
+
+func init() {
+	api.RegisterErrorStatus(LimitExceededError, http.StatusTooManyRequests)
+}
+
+const (
+	headerRetryAfter = "Retry-After"
+	headerLimit      = "RateLimit-Limit"
+	headerRemaining  = "RateLimit-Remaining"
+	headerReset      = "RateLimit-Reset"
+)
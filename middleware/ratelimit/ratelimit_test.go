@@ -0,0 +1,302 @@
+package ratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	apitest "github.com/gotech-labs/api/http/testing"
+	. "github.com/gotech-labs/api/middleware/ratelimit"
+	"github.com/gotech-labs/core/system"
+)
+
+func TestTokenBucket(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		store     = NewMemoryStore()
+		algorithm = TokenBucket(1, time.Second, 3)
+		now       = time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC)
+	)
+	// burst
+	for i := 2; i >= 0; i-- {
+		result, err := algorithm.Allow(ctx, store, "key", now)
+		if assert.NoError(t, err) {
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, i, result.Remaining)
+		}
+	}
+	result, _ := algorithm.Allow(ctx, store, "key", now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// refill
+	result, _ = algorithm.Allow(ctx, store, "key", now.Add(1500*time.Millisecond))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, _ = algorithm.Allow(ctx, store, "key", now.Add(1500*time.Millisecond))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	// other keys are independent
+	result, _ = algorithm.Allow(ctx, store, "other", now)
+	assert.True(t, result.Allowed)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		store     = NewMemoryStore()
+		algorithm = SlidingWindow(4, time.Minute)
+		start     = time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC)
+	)
+	for i := 3; i >= 0; i-- {
+		result, err := algorithm.Allow(ctx, store, "key", start.Add(30*time.Second))
+		if assert.NoError(t, err) {
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Remaining)
+			assert.Equal(t, 30*time.Second, result.Reset)
+		}
+	}
+	result, _ := algorithm.Allow(ctx, store, "key", start.Add(30*time.Second))
+	assert.False(t, result.Allowed)
+	// the current window slides out 15 seconds into the next window: 4 * (1 - 15/60) = 3
+	assert.Equal(t, 45*time.Second, result.RetryAfter)
+
+	// the previous window is weighted by its overlap with the sliding window
+	result, _ = algorithm.Allow(ctx, store, "key", start.Add(70*time.Second))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 5*time.Second, result.RetryAfter)
+	result, _ = algorithm.Allow(ctx, store, "key", start.Add(75*time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// the previous window is forgotten after two windows
+	result, _ = algorithm.Allow(ctx, store, "key", start.Add(3*time.Minute))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 3, result.Remaining)
+}
+
+func TestRateLimit(t *testing.T) {
+	system.RunTest(t, "limit each client", func(t *testing.T) {
+		var (
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				return api.OK("ok")
+			}
+			middleware = New(TokenBucket(1, time.Second, 2)).Middleware()
+			request    = func(key string) api.Response {
+				req := apitest.RequestBuilder{
+					Method:  http.MethodGet,
+					Path:    "/events",
+					Headers: map[string][]string{"X-Api-Key": {key}},
+				}.Build()
+				return middleware(handler)(context.Background(), req)
+			}
+		)
+		resp := request("a")
+		assert.Equal(t, http.StatusOK, resp.Status())
+		assert.Equal(t, "2", resp.HeaderValues().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", resp.HeaderValues().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", resp.HeaderValues().Get("RateLimit-Reset"))
+
+		assert.Equal(t, http.StatusOK, request("a").Status())
+
+		resp = request("a")
+		assert.Equal(t, http.StatusTooManyRequests, resp.Status())
+		assert.Equal(t, "1", resp.HeaderValues().Get("Retry-After"))
+		assert.Equal(t, "0", resp.HeaderValues().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2", resp.HeaderValues().Get("RateLimit-Reset"))
+		if err, ok := resp.Body().(error); assert.True(t, ok) {
+			assert.Equal(t, "Rate limit exceeded: limit=2", err.Error())
+			assert.Equal(t, http.StatusTooManyRequests, api.ErrorStatus(err))
+		}
+	})
+
+	for _, test := range []struct {
+		name     string
+		keyFunc  func(api.Request) string
+		requests []apitest.RequestBuilder
+		expected []int
+	}{
+		{
+			name:    "key by header",
+			keyFunc: KeyByHeader("X-Api-Key"),
+			requests: []apitest.RequestBuilder{
+				{Method: http.MethodGet, Path: "/events", Headers: map[string][]string{"X-Api-Key": {"a"}}},
+				{Method: http.MethodGet, Path: "/events", Headers: map[string][]string{"X-Api-Key": {"b"}}},
+				{Method: http.MethodGet, Path: "/events", Headers: map[string][]string{"X-Api-Key": {"a"}}},
+			},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:    "key by path param",
+			keyFunc: KeyByPathParam("tenant"),
+			requests: []apitest.RequestBuilder{
+				{Method: http.MethodGet, Path: "/t/a", PathParams: map[string]string{"tenant": "a"}},
+				{Method: http.MethodGet, Path: "/t/a", PathParams: map[string]string{"tenant": "a"}},
+				{Method: http.MethodGet, Path: "/t/b", PathParams: map[string]string{"tenant": "b"}},
+			},
+			expected: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:    "key by client ip",
+			keyFunc: KeyByClientIP(),
+			requests: []apitest.RequestBuilder{
+				{Method: http.MethodGet, Path: "/events"},
+				{Method: http.MethodGet, Path: "/events"},
+			},
+			expected: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:    "requests without key are not limited",
+			keyFunc: KeyByHeader("X-Api-Key"),
+			requests: []apitest.RequestBuilder{
+				{Method: http.MethodGet, Path: "/events"},
+				{Method: http.MethodGet, Path: "/events"},
+			},
+			expected: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "custom key",
+			keyFunc: func(req api.Request) string {
+				return req.Method()
+			},
+			requests: []apitest.RequestBuilder{
+				{Method: http.MethodGet, Path: "/a"},
+				{Method: http.MethodPost, Path: "/b"},
+				{Method: http.MethodGet, Path: "/c"},
+			},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	} {
+		system.RunTest(t, test.name, func(t *testing.T) {
+			var (
+				handler = func(ctx context.Context, req api.Request) api.Response {
+					return api.OK("ok")
+				}
+				middleware = New(SlidingWindow(1, time.Minute)).
+						WithKeyFunc(test.keyFunc).
+						Middleware()
+			)
+			for i, rb := range test.requests {
+				resp := middleware(handler)(context.Background(), rb.Build())
+				assert.Equal(t, test.expected[i], resp.Status(), i)
+			}
+		})
+	}
+
+	system.RunTest(t, "fail open on store error", func(t *testing.T) {
+		var (
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				return api.OK("ok")
+			}
+			middleware = New(TokenBucket(1, time.Second, 1)).
+					WithStore(errorStore{}).
+					Middleware()
+			req = apitest.RequestBuilder{Method: http.MethodGet, Path: "/events"}.Build()
+		)
+		resp := middleware(handler)(context.Background(), req)
+		assert.Equal(t, http.StatusOK, resp.Status())
+		assert.Empty(t, resp.HeaderValues().Get("RateLimit-Limit"))
+	})
+}
+
+func TestInvalidAlgorithm(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		algorithm func() Algorithm
+		expected  string
+	}{
+		{
+			name:      "zero rate",
+			algorithm: func() Algorithm { return TokenBucket(0, time.Second, 1) },
+			expected:  "Invalid token bucket: rate=0, per=1s, burst=1",
+		},
+		{
+			name:      "zero burst",
+			algorithm: func() Algorithm { return TokenBucket(1, time.Second, 0) },
+			expected:  "Invalid token bucket: rate=1, per=1s, burst=0",
+		},
+		{
+			name:      "too high rate",
+			algorithm: func() Algorithm { return TokenBucket(10, time.Nanosecond, 1) },
+			expected:  "Invalid token bucket: rate=10 is too high for per=1ns",
+		},
+		{
+			name:      "zero limit",
+			algorithm: func() Algorithm { return SlidingWindow(0, time.Minute) },
+			expected:  "Invalid sliding window: limit=0, window=1m0s",
+		},
+		{
+			name:      "zero window",
+			algorithm: func() Algorithm { return SlidingWindow(1, 0) },
+			expected:  "Invalid sliding window: limit=1, window=0s",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.PanicsWithValue(t, test.expected, func() { test.algorithm() })
+		})
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemoryStore()
+		now   = time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC)
+		count = func(now time.Time) int {
+			var count int
+			_ = store.Update(ctx, "key", now, time.Minute, func(state *State) {
+				state.Count++
+				count = state.Count
+			})
+			return count
+		}
+	)
+	assert.Equal(t, 1, count(now))
+	assert.Equal(t, 2, count(now.Add(time.Minute)))
+	// expired by the clock passed in, not the wall clock
+	assert.Equal(t, 1, count(now.Add(2*time.Minute+time.Second)))
+}
+
+func TestMemoryStoreConcurrency(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		store     = NewMemoryStore()
+		algorithm = TokenBucket(1, time.Hour, 100)
+		now       = time.Now()
+		allowed   = make(chan bool, 400)
+		wg        sync.WaitGroup
+	)
+	for i := 0; i < 400; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, _ := algorithm.Allow(ctx, store, fmt.Sprintf("key-%d", i%2), now)
+			allowed <- result.Allowed
+		}(i)
+	}
+	wg.Wait()
+	close(allowed)
+
+	count := 0
+	for a := range allowed {
+		if a {
+			count++
+		}
+	}
+	assert.Equal(t, 200, count)
+}
+
+type errorStore struct{}
+
+func (errorStore) Update(context.Context, string, time.Time, time.Duration, func(*State)) error {
+	return fmt.Errorf("connection refused")
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// State is the rate limit state of a key.
+type State struct {
+	// Tokens is the number of tokens left in the bucket.
+	Tokens float64
+	// Count and PrevCount are the number of requests in the current and previous windows.
+	Count     int
+	PrevCount int
+	// Timestamp is the last refill of the bucket, or the start of the current window.
+	Timestamp time.Time
+}
+
+// Store keeps the states of the keys. External backends, e.g. Redis, implement it to share
+// the limits between servers.
+type Store interface {
+	// Update calls fn with the state of the key, which is the zero State for new or expired keys,
+	// and saves the modified state for ttl from now. Expiry is decided with now, the clock of the algorithm,
+	// instead of the clock of the store. Updates of the same key must be atomic.
+	Update(ctx context.Context, key string, now time.Time, ttl time.Duration, fn func(state *State)) error
+}
+
+// NewMemoryStore returns the in-memory store. Keys are spread over shards to reduce lock contention,
+// and expired keys are swept while updating.
+func NewMemoryStore() Store {
+	s := &memoryStore{}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]*memoryEntry)
+	}
+	return s
+}
+
+type memoryStore struct {
+	shards [shardCount]memoryShard
+}
+
+type memoryShard struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	state   State
+	expires time.Time
+}
+
+func (s *memoryStore) Update(_ context.Context, key string, now time.Time, ttl time.Duration, fn func(state *State)) error {
+	shard := s.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if now.Sub(shard.lastSweep) > sweepInterval {
+		for k, entry := range shard.entries {
+			if now.After(entry.expires) {
+				delete(shard.entries, k)
+			}
+		}
+		shard.lastSweep = now
+	}
+	entry, ok := shard.entries[key]
+	if !ok || now.After(entry.expires) {
+		entry = &memoryEntry{}
+		shard.entries[key] = entry
+	}
+	fn(&entry.state)
+	entry.expires = now.Add(ttl)
+	return nil
+}
+
+func (s *memoryStore) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &s.shards[h.Sum32()%shardCount]
+}
+
+const (
+	shardCount    = 64
+	sweepInterval = time.Minute
+)
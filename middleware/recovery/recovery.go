@@ -6,6 +6,7 @@ import (
 	"io"
 
 	"github.com/gotech-labs/api"
+	"github.com/gotech-labs/api/middleware/logger"
 	"github.com/gotech-labs/core/log"
 )
 
@@ -28,7 +29,7 @@ func (mw *recovery) Middleware() api.MiddlewareFunc {
 					if !ok {
 						err = fmt.Errorf("%v", r)
 					}
-					mw.logger.Error().Stack().Err(err).Msg("panic recovered")
+					logger.With(ctx, mw.logger).Error().Stack().Err(err).Msg("panic recovered")
 					resp = api.InternalServerError(err)
 				}
 			}()
@@ -0,0 +1,109 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gotech-labs/api"
+)
+
+// HeaderName is the default header carrying the request id.
+const HeaderName = "X-Request-Id"
+
+func New() *requestID {
+	return &requestID{
+		header:    HeaderName,
+		generator: generate,
+	}
+}
+
+type requestID struct {
+	header    string
+	generator func() string
+}
+
+func (mw *requestID) Middleware() api.MiddlewareFunc {
+	return func(next api.HandlerFunc) api.HandlerFunc {
+		return func(ctx context.Context, req api.Request) api.Response {
+			id := ""
+			if values := req.Header(mw.header); len(values) > 0 && valid(values[0]) {
+				id = values[0]
+			} else {
+				id = mw.generator()
+			}
+			// pass the request id through the request context
+			resp := next(NewContext(ctx, id), req)
+			if resp == nil {
+				return resp
+			}
+			return resp.WithHeader(mw.header, id)
+		}
+	}
+}
+
+// WithHeader changes the header carrying the request id.
+// The middlewares reading it with FromResponse are given the same header.
+func (mw *requestID) WithHeader(header string) *requestID {
+	mw.header = header
+	return mw
+}
+
+// WithGenerator changes the generator of request ids for requests without a valid id.
+func (mw *requestID) WithGenerator(generator func() string) *requestID {
+	mw.generator = generator
+	return mw
+}
+
+// NewContext returns the context carrying the request id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id of the context, or an empty string.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// FromResponse returns the request id echoed on the response in the header, or an empty string.
+// It is used by middlewares running outside of the requestid middleware.
+func FromResponse(resp api.Response, header string) string {
+	if resp == nil {
+		return ""
+	}
+	return resp.HeaderValues().Get(header)
+}
+
+type contextKey struct{}
+
+// valid accepts ids of visible ASCII characters only, so that clients can not inject into logs.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// generate returns a random UUID version 4.
+func generate() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+const (
+	maxLength = 128
+)
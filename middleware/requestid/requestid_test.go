@@ -0,0 +1,101 @@
+package requestid_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	apitest "github.com/gotech-labs/api/http/testing"
+	. "github.com/gotech-labs/api/middleware/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, test := range []struct {
+		name     string
+		headers  map[string][]string
+		expected func(t *testing.T, id string)
+	}{
+		{
+			name:    "propagate request id",
+			headers: map[string][]string{"X-Request-Id": {"req-12345"}},
+			expected: func(t *testing.T, id string) {
+				assert.Equal(t, "req-12345", id)
+			},
+		},
+		{
+			name: "generate request id",
+			expected: func(t *testing.T, id string) {
+				assert.Regexp(t, uuid, id)
+			},
+		},
+		{
+			name:    "regenerate invalid request id",
+			headers: map[string][]string{"X-Request-Id": {"req 12345\n{\"level\":\"error\"}"}},
+			expected: func(t *testing.T, id string) {
+				assert.Regexp(t, uuid, id)
+			},
+		},
+		{
+			name:    "regenerate too long request id",
+			headers: map[string][]string{"X-Request-Id": {strings.Repeat("a", 129)}},
+			expected: func(t *testing.T, id string) {
+				assert.Regexp(t, uuid, id)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				req = apitest.RequestBuilder{
+					Method:  http.MethodGet,
+					Path:    "/events",
+					Headers: test.headers,
+				}.Build()
+				ctxID   string
+				handler = func(ctx context.Context, req api.Request) api.Response {
+					ctxID = FromContext(ctx)
+					return api.OK("ok")
+				}
+			)
+			resp := New().Middleware()(handler)(context.Background(), req)
+
+			test.expected(t, ctxID)
+			assert.Equal(t, ctxID, resp.Headers()["X-Request-Id"])
+			assert.Equal(t, ctxID, FromResponse(resp, HeaderName))
+		})
+	}
+
+	t.Run("custom header and generator", func(t *testing.T) {
+		var (
+			req = apitest.RequestBuilder{
+				Method:  http.MethodGet,
+				Path:    "/events",
+				Headers: map[string][]string{"X-Correlation-Id": {"corr-1"}},
+			}.Build()
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				return api.OK(FromContext(ctx))
+			}
+			mw = New().
+				WithHeader("X-Correlation-Id").
+				WithGenerator(func() string { return "generated" }).
+				Middleware()
+		)
+		resp := mw(handler)(context.Background(), req)
+		assert.Equal(t, "corr-1", resp.Headers()["X-Correlation-Id"])
+		assert.Equal(t, "corr-1", FromResponse(resp, "X-Correlation-Id"))
+		assert.Equal(t, "", FromResponse(resp, HeaderName))
+
+		req = apitest.RequestBuilder{Method: http.MethodGet, Path: "/events"}.Build()
+		resp = mw(handler)(context.Background(), req)
+		assert.Equal(t, "generated", resp.Headers()["X-Correlation-Id"])
+	})
+
+	t.Run("empty context", func(t *testing.T) {
+		assert.Equal(t, "", FromContext(context.Background()))
+	})
+}
@@ -0,0 +1,167 @@
+package timeout
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotech-labs/api"
+	"github.com/gotech-labs/api/middleware/logger"
+)
+
+// New returns the middleware putting the deadline on the context passed to the handler.
+// It is applied per route by passing it to the route, e.g. router.GET(path, h, timeout.New(time.Second).Middleware()).
+func New(d time.Duration) *timeout {
+	return &timeout{
+		timeout:  d,
+		header:   HeaderName,
+		response: api.Timeout,
+	}
+}
+
+type timeout struct {
+	timeout  time.Duration
+	header   string
+	response func(err error) api.Response
+}
+
+// Middleware runs the handler in a goroutine and responds the timeout response when the handler overruns.
+// The late response of the handler is discarded, and its panic is logged instead of crashing the server.
+// Handlers should return soon after the context is done, since they keep running until then.
+// The context of stream and upgrade responses is kept until the deadline, since their bodies are written
+// after the handler returns, so the deadline covers streaming as well.
+// The request body and form are read before the handler starts, since the request is closed when
+// the timeout response is written, e.g. removing the temporary files of multipart forms.
+func (mw *timeout) Middleware() api.MiddlewareFunc {
+	return func(next api.HandlerFunc) api.HandlerFunc {
+		return func(ctx context.Context, req api.Request) api.Response {
+			d := mw.timeout
+			if clientTimeout, ok := mw.clientTimeout(req); ok {
+				if clientTimeout <= 0 {
+					return mw.response(api.TimeoutError.New("Request deadline exceeded: client deadline has passed"))
+				}
+				if clientTimeout < d {
+					d = clientTimeout
+				}
+			}
+			ctx, cancel := context.WithTimeout(ctx, d)
+			keep := false
+			defer func() {
+				if !keep {
+					cancel()
+				}
+			}()
+			readBody(req)
+			if ctx.Err() != nil {
+				return mw.timeoutResponse(req, d)
+			}
+
+			// buffered, so that the late handler does not block forever
+			done := make(chan result, 1)
+			go func() {
+				var res result
+				defer func() {
+					if r := recover(); r != nil {
+						res.panicked, res.recovered = true, r
+					}
+					done <- res
+				}()
+				res.resp = next(ctx, req)
+			}()
+
+			select {
+			case res := <-done:
+				if res.panicked {
+					// the recovery middleware outside handles it
+					panic(res.recovered)
+				}
+				// the context is released at the deadline, or when the server cancels the request context
+				keep = isStreaming(res.resp)
+				return res.resp
+			case <-ctx.Done():
+				go discard(ctx, req, done)
+				return mw.timeoutResponse(req, d)
+			}
+		}
+	}
+}
+
+func (mw *timeout) timeoutResponse(req api.Request, d time.Duration) api.Response {
+	return mw.response(api.TimeoutError.New(fmt.Sprintf(
+		"Request deadline exceeded: method=%v, path=%v, timeout=%v", req.Method(), req.Path(), d)))
+}
+
+// WithClientDeadlineHeader changes the header of the client deadline. An empty name ignores client deadlines.
+func (mw *timeout) WithClientDeadlineHeader(name string) *timeout {
+	mw.header = name
+	return mw
+}
+
+// WithResponse changes the response to overrunning handlers, e.g. api.ServiceUnavailable.
+func (mw *timeout) WithResponse(response func(err error) api.Response) *timeout {
+	mw.response = response
+	return mw
+}
+
+// clientTimeout parses the client deadline header, in milliseconds or as a duration, e.g. "1500" or "1.5s".
+func (mw *timeout) clientTimeout(req api.Request) (time.Duration, bool) {
+	if mw.header == "" {
+		return 0, false
+	}
+	values := req.Header(mw.header)
+	if len(values) == 0 {
+		return 0, false
+	}
+	value := strings.TrimSpace(values[0])
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, true
+	}
+	return 0, false
+}
+
+// readBody buffers the body, or parses the form, so that the handler only reads the buffered request.
+// The errors are kept by the request and returned to the handler when it reads them.
+func readBody(req api.Request) {
+	_ = req.FormValue("")
+	_, _ = req.ReadBody()
+}
+
+// discard waits for the late handler and drops its response.
+func discard(ctx context.Context, req api.Request, done <-chan result) {
+	res := <-done
+	l := logger.FromContext(ctx)
+	if res.panicked {
+		l.Error().Str("method", req.Method()).Str("path", req.Path()).
+			Interface("panic", res.recovered).Msg("panic recovered after timeout")
+		return
+	}
+	if res.resp != nil {
+		l.Warn().Str("method", req.Method()).Str("path", req.Path()).
+			Int("status", res.resp.Status()).Msg("late response discarded")
+	}
+}
+
+// isStreaming reports whether the body of the response is written with the context after the handler returns.
+func isStreaming(resp api.Response) bool {
+	switch resp.(type) {
+	case api.StreamResponse, api.UpgradeResponse:
+		return true
+	}
+	return false
+}
+
+type result struct {
+	resp      api.Response
+	panicked  bool
+	recovered interface{}
+}
+
+const (
+	// HeaderName is the default header of the client deadline.
+	HeaderName = "X-Request-Timeout"
+)
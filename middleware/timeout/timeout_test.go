@@ -0,0 +1,258 @@
+package timeout_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	apihttp "github.com/gotech-labs/api/http"
+	apitest "github.com/gotech-labs/api/http/testing"
+	. "github.com/gotech-labs/api/middleware/timeout"
+	reqbuilder "github.com/gotech-labs/api/testing"
+)
+
+func TestTimeout(t *testing.T) {
+	var (
+		fast = func(ctx context.Context, req api.Request) api.Response {
+			return api.OK("ok")
+		}
+		slow = func(ctx context.Context, req api.Request) api.Response {
+			<-ctx.Done()
+			return api.OK("late")
+		}
+	)
+	for _, test := range []struct {
+		name     string
+		mw       api.MiddlewareFunc
+		handler  api.HandlerFunc
+		headers  map[string][]string
+		expected int
+		message  string
+	}{
+		{
+			name:     "handler in time",
+			mw:       New(time.Second).Middleware(),
+			handler:  fast,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "handler overruns",
+			mw:       New(10 * time.Millisecond).Middleware(),
+			handler:  slow,
+			expected: http.StatusGatewayTimeout,
+			message:  "Request deadline exceeded: method=GET, path=/events, timeout=10ms",
+		},
+		{
+			name:     "custom response",
+			mw:       New(10 * time.Millisecond).WithResponse(api.ServiceUnavailable).Middleware(),
+			handler:  slow,
+			expected: http.StatusServiceUnavailable,
+			message:  "Request deadline exceeded: method=GET, path=/events, timeout=10ms",
+		},
+		{
+			name:     "client deadline in milliseconds",
+			mw:       New(time.Minute).Middleware(),
+			handler:  slow,
+			headers:  map[string][]string{"X-Request-Timeout": {"20"}},
+			expected: http.StatusGatewayTimeout,
+			message:  "Request deadline exceeded: method=GET, path=/events, timeout=20ms",
+		},
+		{
+			name:     "client deadline as duration",
+			mw:       New(time.Minute).Middleware(),
+			handler:  slow,
+			headers:  map[string][]string{"X-Request-Timeout": {"15ms"}},
+			expected: http.StatusGatewayTimeout,
+			message:  "Request deadline exceeded: method=GET, path=/events, timeout=15ms",
+		},
+		{
+			name:     "client deadline longer than route timeout",
+			mw:       New(10 * time.Millisecond).Middleware(),
+			handler:  slow,
+			headers:  map[string][]string{"X-Request-Timeout": {"60000"}},
+			expected: http.StatusGatewayTimeout,
+			message:  "Request deadline exceeded: method=GET, path=/events, timeout=10ms",
+		},
+		{
+			name:     "invalid client deadline",
+			mw:       New(time.Second).Middleware(),
+			handler:  fast,
+			headers:  map[string][]string{"X-Request-Timeout": {"soon"}},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "client deadline ignored",
+			mw:       New(time.Second).WithClientDeadlineHeader("").Middleware(),
+			handler:  fast,
+			headers:  map[string][]string{"X-Request-Timeout": {"0"}},
+			expected: http.StatusOK,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := apitest.RequestBuilder{
+				Method:  http.MethodGet,
+				Path:    "/events",
+				Headers: test.headers,
+			}.Build()
+			resp := test.mw(test.handler)(context.Background(), req)
+
+			assert.Equal(t, test.expected, resp.Status())
+			if test.message != "" {
+				if err, ok := resp.Body().(error); assert.True(t, ok) {
+					assert.Equal(t, test.message, err.Error())
+				}
+			}
+		})
+	}
+
+	t.Run("client deadline has passed", func(t *testing.T) {
+		var (
+			req = apitest.RequestBuilder{
+				Method:  http.MethodGet,
+				Path:    "/events",
+				Headers: map[string][]string{"X-Request-Timeout": {"0"}},
+			}.Build()
+			called  bool
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				called = true
+				return api.OK("ok")
+			}
+		)
+		resp := New(time.Second).Middleware()(handler)(context.Background(), req)
+		assert.Equal(t, http.StatusGatewayTimeout, resp.Status())
+		assert.False(t, called)
+	})
+
+	t.Run("deadline on context", func(t *testing.T) {
+		var (
+			req      = apitest.RequestBuilder{Method: http.MethodGet, Path: "/events"}.Build()
+			deadline time.Time
+			handler  = func(ctx context.Context, req api.Request) api.Response {
+				deadline, _ = ctx.Deadline()
+				return api.OK("ok")
+			}
+		)
+		start := time.Now()
+		New(time.Second).Middleware()(handler)(context.Background(), req)
+		assert.WithinDuration(t, start.Add(time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("event stream", func(t *testing.T) {
+		var (
+			req     = httptest.NewRequest(http.MethodGet, "/events", nil)
+			rec     = httptest.NewRecorder()
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				events := make(chan api.Event, 2)
+				events <- api.Event{ID: "1", Data: "hello"}
+				events <- api.Event{ID: "2", Data: "world"}
+				close(events)
+				return api.EventStream(ctx, events)
+			}
+		)
+		apihttp.NewHandler(handler, New(5*time.Second).Middleware()).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "id: 1\ndata: hello\n\nid: 2\ndata: world\n\n", rec.Body.String())
+	})
+
+	t.Run("stream checking context", func(t *testing.T) {
+		var (
+			req     = httptest.NewRequest(http.MethodGet, "/export", nil)
+			rec     = httptest.NewRecorder()
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				return api.StreamFunc(http.StatusOK, "text/csv", func(w io.Writer) error {
+					for i := 1; i <= 3; i++ {
+						if err := ctx.Err(); err != nil {
+							return err
+						}
+						if _, err := fmt.Fprintf(w, "%d\n", i); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			}
+		)
+		apihttp.NewHandler(handler, New(5*time.Second).Middleware()).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1\n2\n3\n", rec.Body.String())
+	})
+
+	t.Run("multipart form read after timeout", func(t *testing.T) {
+		var (
+			dir      = t.TempDir()
+			served   = make(chan struct{})
+			finished = make(chan struct{})
+			file     *api.FormFile
+			handler  = func(ctx context.Context, req api.Request) api.Response {
+				defer close(finished)
+				<-ctx.Done()
+				// the timeout response has been written and the request closed
+				<-served
+				file = req.FormFile("photo")
+				return api.NoContent()
+			}
+			req = reqbuilder.RequestBuilder{
+				Method: http.MethodPost,
+				Path:   "/events",
+				Files: []apitest.File{
+					{Field: "photo", Filename: "a.png", Content: bytes.Repeat([]byte("a"), 1024)},
+				},
+			}.Build()
+			rec = httptest.NewRecorder()
+		)
+		t.Setenv("TMPDIR", dir)
+		defaultMemory := apihttp.DefaultMaxMultipartMemory
+		apihttp.DefaultMaxMultipartMemory = 1
+		defer func() { apihttp.DefaultMaxMultipartMemory = defaultMemory }()
+
+		apihttp.NewHandler(handler, New(10*time.Millisecond).Middleware()).ServeHTTP(rec, req)
+		close(served)
+		<-finished
+
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+		if assert.NotNil(t, file) {
+			_, err := file.Open()
+			assert.Error(t, err)
+		}
+		remaining, _ := os.ReadDir(dir)
+		assert.Empty(t, remaining)
+	})
+
+	t.Run("panic in time", func(t *testing.T) {
+		var (
+			req     = apitest.RequestBuilder{Method: http.MethodGet, Path: "/events"}.Build()
+			handler = func(ctx context.Context, req api.Request) api.Response {
+				panic("unexpected")
+			}
+		)
+		assert.PanicsWithValue(t, "unexpected", func() {
+			New(time.Second).Middleware()(handler)(context.Background(), req)
+		})
+	})
+
+	t.Run("panic after timeout", func(t *testing.T) {
+		var (
+			req      = apitest.RequestBuilder{Method: http.MethodGet, Path: "/events"}.Build()
+			panicked = make(chan struct{})
+			handler  = func(ctx context.Context, req api.Request) api.Response {
+				<-ctx.Done()
+				defer close(panicked)
+				panic("late")
+			}
+		)
+		resp := New(10*time.Millisecond).Middleware()(handler)(context.Background(), req)
+		assert.Equal(t, http.StatusGatewayTimeout, resp.Status())
+		<-panicked
+	})
+}
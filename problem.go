@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gotech-labs/core/errors"
+	"golang.org/x/xerrors"
+)
+
+// Problem is an error response body defined by RFC 7807 (application/problem+json).
+// A Problem passed to an error response constructor is always rendered as problem details.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+	err        error
+}
+
+// NewProblem returns problem details derived from the error.
+// The type and title are looked up from the problem type registry by the TypedError of the error.
+func NewProblem(status int, err error) *Problem {
+	var p *Problem
+	if xerrors.As(err, &p) {
+		if p.Status == 0 {
+			p.Status = status
+		}
+		return p
+	}
+	p = &Problem{
+		Type:   problemTypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		err:    err,
+	}
+	if err != nil {
+		p.Detail = err.Error()
+	}
+	if errs := fieldErrorsOf(err); errs != nil {
+		p.WithExtension("errors", errs)
+	}
+	if typed, ok := errorTypeOf(err); ok {
+		if pt, ok := LookupProblemType(typed); ok {
+			p.Type = pt.URI
+			if pt.Title != "" {
+				p.Title = pt.Title
+			}
+		}
+	}
+	return p
+}
+
+// WithInstance sets the URI reference that identifies the occurrence of the problem.
+func (p *Problem) WithInstance(instance string) *Problem {
+	p.Instance = instance
+	return p
+}
+
+// WithExtension adds the extension member to the problem details.
+func (p *Problem) WithExtension(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+func (p *Problem) Unwrap() error {
+	return p.err
+}
+
+// MarshalJSON renders the problem details with the extension members at the top level.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = problemTypeBlank
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// ProblemType is the type URI and the title of problem details.
+type ProblemType struct {
+	URI   string
+	Title string
+}
+
+// RegisterProblemType maps the TypedError to the problem type.
+func RegisterProblemType(typed errors.TypedError, pt ProblemType) {
+	problemTypes.Store(typed, pt)
+}
+
+// LookupProblemType returns the problem type registered for the TypedError.
+func LookupProblemType(typed errors.TypedError) (ProblemType, bool) {
+	pt, ok := problemTypes.Load(typed)
+	if !ok {
+		return ProblemType{}, false
+	}
+	return pt.(ProblemType), true
+}
+
+// UseProblemDetails switches error responses to application/problem+json.
+func UseProblemDetails(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&problemDetailsEnabled, value)
+}
+
+func problemDetailsMode() bool {
+	return atomic.LoadInt32(&problemDetailsEnabled) == 1
+}
+
+// errorTypeOf returns the TypedError of the first core error in the wrapped chain.
+func errorTypeOf(err error) (errors.TypedError, bool) {
+	var typed interface {
+		Type() errors.TypedError
+	}
+	if err != nil && xerrors.As(err, &typed) {
+		return typed.Type(), true
+	}
+	var zero errors.TypedError
+	return zero, false
+}
+
+var (
+	problemTypes          sync.Map
+	problemDetailsEnabled int32
+)
+
+func init() {
+	RegisterProblemType(BindingError, ProblemType{URI: "/problems/binding-error", Title: "Binding Error"})
+	RegisterProblemType(JSONEncodeError, ProblemType{URI: "/problems/json-encode-error", Title: "JSON Encode Error"})
+	RegisterProblemType(RoutingError, ProblemType{URI: "/problems/routing-error", Title: "Routing Error"})
+	RegisterProblemType(EncodeError, ProblemType{URI: "/problems/encode-error", Title: "Encode Error"})
+	RegisterProblemType(FormError, ProblemType{URI: "/problems/form-error", Title: "Form Error"})
+	RegisterProblemType(TimeoutError, ProblemType{URI: "/problems/timeout-error", Title: "Timeout Error"})
+}
+
+const (
+	problemTypeBlank = "about:blank"
+)
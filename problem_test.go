@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+	"github.com/gotech-labs/core/errors"
+)
+
+func TestProblemDetails(t *testing.T) {
+	UseProblemDetails(true)
+	defer UseProblemDetails(false)
+
+	for _, test := range []struct {
+		name     string
+		response Response
+		expected string
+	}{
+		{
+			name:     "registered error type",
+			response: BadRequest(BindingError.New("Syntax error: offset=90")),
+			expected: `{
+				"type": "/problems/binding-error",
+				"title": "Binding Error",
+				"status": 400,
+				"detail": "Syntax error: offset=90"
+			}`,
+		},
+		{
+			name:     "wrapped error type",
+			response: InternalServerError(fmt.Errorf("wrapped: %w", JSONEncodeError.New("encode error"))),
+			expected: `{
+				"type": "/problems/json-encode-error",
+				"title": "JSON Encode Error",
+				"status": 500,
+				"detail": "wrapped: encode error"
+			}`,
+		},
+		{
+			name:     "unregistered error type",
+			response: NotFound(fmt.Errorf("event not found")),
+			expected: `{
+				"type": "about:blank",
+				"title": "Not Found",
+				"status": 404,
+				"detail": "event not found"
+			}`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			actual := test.response
+			assert.Equal(t, "application/problem+json", actual.Headers()["Content-Type"])
+			assert.JSONEq(t, test.expected, string(actual.BodyJSON()))
+		})
+	}
+
+	t.Run("success response", func(t *testing.T) {
+		actual := OK("OK")
+		assert.Equal(t, "application/json", actual.Headers()["Content-Type"])
+		assert.JSONEq(t, `{"message": "OK"}`, string(actual.BodyJSON()))
+	})
+}
+
+func TestProblem(t *testing.T) {
+	t.Run("explicit problem", func(t *testing.T) {
+		problem := NewProblem(http.StatusConflict, errors.ValidationError.New("duplicated name")).
+			WithInstance("/events/123").
+			WithExtension("name", "Michael Jordan")
+		actual := Conflict(problem)
+
+		assert.Equal(t, "application/problem+json", actual.Headers()["Content-Type"])
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Conflict",
+			"status": 409,
+			"detail": "duplicated name",
+			"instance": "/events/123",
+			"name": "Michael Jordan"
+		}`, string(actual.BodyJSON()))
+	})
+
+	t.Run("custom problem type", func(t *testing.T) {
+		typed := errors.TypedError("custom_error")
+		RegisterProblemType(typed, ProblemType{
+			URI:   "https://example.com/problems/custom",
+			Title: "Custom Error",
+		})
+		pt, ok := LookupProblemType(typed)
+		if assert.True(t, ok) {
+			assert.Equal(t, "https://example.com/problems/custom", pt.URI)
+		}
+		problem := NewProblem(http.StatusBadRequest, typed.New("custom"))
+		assert.Equal(t, "https://example.com/problems/custom", problem.Type)
+		assert.Equal(t, "Custom Error", problem.Title)
+	})
+
+	t.Run("problem details disabled", func(t *testing.T) {
+		actual := BadRequest(BindingError.New("binding error"))
+		assert.Equal(t, "application/json", actual.Headers()["Content-Type"])
+	})
+}
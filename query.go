@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// QueryInt returns the query parameter as int, or the default value when it is absent.
+// Malformed values are returned as BindingError.
+func QueryInt(req Request, key string, def int) (int, error) {
+	value := def
+	err := parseQuery(req, key, &value, "")
+	return value, err
+}
+
+// QueryInt64 returns the query parameter as int64, or the default value when it is absent.
+func QueryInt64(req Request, key string, def int64) (int64, error) {
+	value := def
+	err := parseQuery(req, key, &value, "")
+	return value, err
+}
+
+// QueryBool returns the query parameter as bool, or the default value when it is absent.
+func QueryBool(req Request, key string, def bool) (bool, error) {
+	value := def
+	err := parseQuery(req, key, &value, "")
+	return value, err
+}
+
+// QueryFloat returns the query parameter as float64, or the default value when it is absent.
+func QueryFloat(req Request, key string, def float64) (float64, error) {
+	value := def
+	err := parseQuery(req, key, &value, "")
+	return value, err
+}
+
+// QueryDuration returns the query parameter parsed by time.ParseDuration, or the default value when it is absent.
+func QueryDuration(req Request, key string, def time.Duration) (time.Duration, error) {
+	value := def
+	err := parseQuery(req, key, &value, "")
+	return value, err
+}
+
+// QueryTime returns the query parameter parsed with the layout, or the default value when it is absent.
+// An empty layout means RFC 3339.
+func QueryTime(req Request, key, layout string, def time.Time) (time.Time, error) {
+	value := def
+	err := parseQuery(req, key, &value, layout)
+	return value, err
+}
+
+// QueryEnum returns the query parameter when it is one of the allowed values, or the default value when it is absent.
+func QueryEnum(req Request, key, def string, allowed ...string) (string, error) {
+	value := req.QueryParameter(key)
+	if value == "" {
+		return def, nil
+	}
+	for _, a := range allowed {
+		if value == a {
+			return value, nil
+		}
+	}
+	return def, BindingError.New(fmt.Sprintf(
+		"Invalid query parameter: key=%v, value=%v, allowed=%v", key, value, strings.Join(allowed, "|")))
+}
+
+// parseQuery sets the first value of the query parameter to the pointer, which is left unchanged when it is absent.
+func parseQuery(req Request, key string, ptr interface{}, layout string) error {
+	value := req.QueryParameter(key)
+	if value == "" {
+		return nil
+	}
+	v := reflect.ValueOf(ptr).Elem()
+	parsed := reflect.New(v.Type()).Elem()
+	if err := setValue(parsed, value, layout); err != nil {
+		return BindingError.New(fmt.Sprintf(
+			"Invalid query parameter: key=%v, error=%v", key, err.Error()))
+	}
+	v.Set(parsed)
+	return nil
+}
@@ -0,0 +1,123 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+	apitest "github.com/gotech-labs/api/http/testing"
+)
+
+func TestQueryAccessors(t *testing.T) {
+	var (
+		req = apitest.RequestBuilder{
+			Method: http.MethodGet,
+			Path:   "/events?tag=a,b&tag=c",
+			QueryParams: map[string]string{
+				"limit":   "10",
+				"offset":  "9007199254740993",
+				"active":  "true",
+				"ratio":   "0.5",
+				"timeout": "1m30s",
+				"since":   "2022-12-24",
+				"order":   "desc",
+				"invalid": "abc",
+			},
+		}.Build()
+		def = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	t.Run("repeated values", func(t *testing.T) {
+		assert.Equal(t, []string{"a,b", "c"}, req.QueryValues("tag"))
+		assert.Nil(t, req.QueryValues("unknown"))
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		limit, err := QueryInt(req, "limit", 20)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, limit)
+
+		offset, err := QueryInt64(req, "offset", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9007199254740993), offset)
+
+		active, err := QueryBool(req, "active", false)
+		assert.NoError(t, err)
+		assert.True(t, active)
+
+		ratio, err := QueryFloat(req, "ratio", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.5, ratio)
+
+		timeout, err := QueryDuration(req, "timeout", time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, 90*time.Second, timeout)
+
+		since, err := QueryTime(req, "since", "2006-01-02", def)
+		assert.NoError(t, err)
+		assert.Equal(t, "2022-12-24", since.Format("2006-01-02"))
+
+		order, err := QueryEnum(req, "order", "asc", "asc", "desc")
+		assert.NoError(t, err)
+		assert.Equal(t, "desc", order)
+	})
+
+	t.Run("default values", func(t *testing.T) {
+		limit, err := QueryInt(req, "unknown", 20)
+		assert.NoError(t, err)
+		assert.Equal(t, 20, limit)
+
+		since, err := QueryTime(req, "unknown", "", def)
+		assert.NoError(t, err)
+		assert.Equal(t, def, since)
+
+		order, err := QueryEnum(req, "unknown", "asc", "asc", "desc")
+		assert.NoError(t, err)
+		assert.Equal(t, "asc", order)
+	})
+
+	t.Run("malformed values", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			parse    func() error
+			expected string
+		}{
+			{
+				name:     "int",
+				parse:    func() error { _, err := QueryInt(req, "invalid", 0); return err },
+				expected: `Invalid query parameter: key=invalid, error=invalid int "abc"`,
+			},
+			{
+				name:     "bool",
+				parse:    func() error { _, err := QueryBool(req, "invalid", false); return err },
+				expected: `Invalid query parameter: key=invalid, error=invalid bool "abc"`,
+			},
+			{
+				name:     "duration",
+				parse:    func() error { _, err := QueryDuration(req, "invalid", 0); return err },
+				expected: `Invalid query parameter: key=invalid, error=invalid duration "abc"`,
+			},
+			{
+				name:     "time",
+				parse:    func() error { _, err := QueryTime(req, "invalid", "", def); return err },
+				expected: `Invalid query parameter: key=invalid, error=invalid time "abc": layout=2006-01-02T15:04:05Z07:00`,
+			},
+			{
+				name:     "enum",
+				parse:    func() error { _, err := QueryEnum(req, "invalid", "asc", "asc", "desc"); return err },
+				expected: "Invalid query parameter: key=invalid, value=abc, allowed=asc|desc",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				err := tc.parse()
+				if assert.Error(t, err) {
+					assert.Equal(t, tc.expected, err.Error())
+					assert.Equal(t, http.StatusBadRequest, ErrorStatus(err))
+				}
+			})
+		}
+	})
+}
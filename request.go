@@ -5,10 +5,12 @@ type Request interface {
 	Method() string
 	Path() string
 	Body() []byte
+	ReadBody() ([]byte, error)
 	Headers() map[string][]string
 	Header(key string) []string
 	QueryParameter(key string) string
 	QueryParameters() map[string]string
+	QueryValues(key string) []string
 	PathParameter(key string) string
 	ClientIP() string
 	UserAgent() string
@@ -16,6 +18,13 @@ type Request interface {
 	Domain() string
 	Protocol() string
 	Host() string
+	Scheme() string
+	Port() string
+	URL(ref string) string
 	ContentLength() int64
+	FormValue(key string) string
+	FormValues(key string) []string
+	FormFile(key string) *FormFile
+	MultipartFiles(fn func(file *FormFile) error) error
 	Bind(obj interface{}) error
 }
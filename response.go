@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/gotech-labs/core/errors"
+	"golang.org/x/xerrors"
 )
 
 // Response is ...
@@ -15,14 +16,22 @@ type Response interface {
 	Body() interface{}
 	BodyJSON() []byte
 	Headers() map[string]string
+	HeaderValues() http.Header
 	WithHeader(string, string) Response
+	AddHeader(string, string) Response
+	DelHeader(string) Response
+	WithCookie(*http.Cookie) Response
+	Encoding() string
+	WithEncoding(string) Response
 }
 
 // response is ...
 type response struct {
-	status  int
-	body    interface{}
-	headers map[string]string
+	status   int
+	body     interface{}
+	headers  http.Header
+	problem  bool
+	encoding string
 }
 
 // Status is ...
@@ -32,8 +41,14 @@ func (r *response) Status() int {
 
 // Body is ...
 func (r *response) Body() interface{} {
+	if r.problem {
+		return NewProblem(r.status, r.body.(error))
+	}
 	switch body := r.body.(type) {
 	case errors.Error:
+		if errs := fieldErrorsOf(body); errs != nil {
+			return &fieldErrorsBody{Error: body, Errors: errs}
+		}
 		return body
 	case error:
 		return errors.UnexpectedError.Wrap(body)
@@ -58,26 +73,74 @@ func (r *response) BodyJSON() []byte {
 		return nil
 	}
 	buf := new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(r.Body())
+	err := json.NewEncoder(buf).Encode(body)
 	if err != nil {
 		panic(JSONEncodeError.Wrapf(err,
 			"Failed to encode json object: error=%v", err.Error()))
 	}
-	println(buf.String())
 	return buf.Bytes()
 }
 
-// Headers is ...
+// Headers returns the first value of each header. Use HeaderValues for repeated headers.
 func (r *response) Headers() map[string]string {
+	headers := make(map[string]string, len(r.headers))
+	for key := range r.headers {
+		headers[key] = r.headers.Get(key)
+	}
+	return headers
+}
+
+// HeaderValues returns all values of each header.
+func (r *response) HeaderValues() http.Header {
 	return r.headers
 }
 
-// WithHeader is ...
+// WithHeader replaces the values of the header.
 func (r *response) WithHeader(key, value string) Response {
-	r.headers[key] = value
+	r.headers.Set(key, value)
+	return r
+}
+
+// AddHeader appends the value to the header, e.g. Vary.
+func (r *response) AddHeader(key, value string) Response {
+	r.headers.Add(key, value)
+	return r
+}
+
+// DelHeader removes the header.
+func (r *response) DelHeader(key string) Response {
+	r.headers.Del(key)
+	return r
+}
+
+// WithCookie adds the Set-Cookie header. Invalid cookies are ignored.
+func (r *response) WithCookie(cookie *http.Cookie) Response {
+	if v := cookie.String(); v != "" {
+		r.headers.Add(headerSetCookie, v)
+	}
+	return r
+}
+
+// Encoding returns the media type forced by WithEncoding.
+func (r *response) Encoding() string {
+	return r.encoding
+}
+
+// WithEncoding forces the media type of the body regardless of the Accept header,
+// e.g. "text/csv" for export endpoints. The media type must have a registered encoder.
+func (r *response) WithEncoding(mediaType string) Response {
+	r.encoding = mediaType
+	if enc, ok := LookupEncoder(mediaType); ok {
+		r.headers.Set(headerContentType, enc.ContentType())
+	}
 	return r
 }
 
+// Status returns the response with the status code, for the codes that have no constructor.
+func Status(status int, body interface{}) Response {
+	return newResponse(status, body)
+}
+
 // OK is ...
 func OK(body interface{}) Response {
 	return newResponse(http.StatusOK, body)
@@ -88,11 +151,36 @@ func Created(body interface{}) Response {
 	return newResponse(http.StatusCreated, body)
 }
 
+// Accepted is ...
+func Accepted(body interface{}) Response {
+	return newResponse(http.StatusAccepted, body)
+}
+
 // NoContent is ...
 func NoContent() Response {
 	return newResponse(http.StatusNoContent, nil)
 }
 
+// MovedPermanently is ...
+func MovedPermanently(location string) Response {
+	return newRedirectResponse(http.StatusMovedPermanently, location)
+}
+
+// Found is ...
+func Found(location string) Response {
+	return newRedirectResponse(http.StatusFound, location)
+}
+
+// TemporaryRedirect is ...
+func TemporaryRedirect(location string) Response {
+	return newRedirectResponse(http.StatusTemporaryRedirect, location)
+}
+
+// PermanentRedirect is ...
+func PermanentRedirect(location string) Response {
+	return newRedirectResponse(http.StatusPermanentRedirect, location)
+}
+
 // BadRequest is ...
 func BadRequest(err error) Response {
 	return newResponse(http.StatusBadRequest, err)
@@ -103,32 +191,112 @@ func Unauthorized(err error) Response {
 	return newResponse(http.StatusUnauthorized, err)
 }
 
+// Forbidden is ...
+func Forbidden(err error) Response {
+	return newResponse(http.StatusForbidden, err)
+}
+
 // NotFound is ...
 func NotFound(err error) Response {
 	return newResponse(http.StatusNotFound, err)
 }
 
+// MethodNotAllowed is ...
+func MethodNotAllowed(err error) Response {
+	return newResponse(http.StatusMethodNotAllowed, err)
+}
+
 // ProxyAuthRequired is ...
 func ProxyAuthRequired(err error) Response {
 	return newResponse(http.StatusProxyAuthRequired, err)
 }
 
+// NotAcceptable is ...
+func NotAcceptable(err error) Response {
+	return newResponse(http.StatusNotAcceptable, err)
+}
+
 // Conflict is ...
 func Conflict(err error) Response {
 	return newResponse(http.StatusConflict, err)
 }
 
+// Gone is ...
+func Gone(err error) Response {
+	return newResponse(http.StatusGone, err)
+}
+
+// PreconditionFailed is ...
+func PreconditionFailed(err error) Response {
+	return newResponse(http.StatusPreconditionFailed, err)
+}
+
+// RequestEntityTooLarge is ...
+func RequestEntityTooLarge(err error) Response {
+	return newResponse(http.StatusRequestEntityTooLarge, err)
+}
+
+// UnprocessableEntity is ...
+func UnprocessableEntity(err error) Response {
+	return newResponse(http.StatusUnprocessableEntity, err)
+}
+
+// TooManyRequests is ...
+func TooManyRequests(err error) Response {
+	return newResponse(http.StatusTooManyRequests, err)
+}
+
 // InternalServerError is ...
 func InternalServerError(err error) Response {
 	return newResponse(http.StatusInternalServerError, err)
 }
 
+// ServiceUnavailable is ...
+func ServiceUnavailable(err error) Response {
+	return newResponse(http.StatusServiceUnavailable, err)
+}
+
+// GatewayTimeout is ...
+func GatewayTimeout(err error) Response {
+	return newResponse(http.StatusGatewayTimeout, err)
+}
+
+// Timeout is the gateway timeout response for handlers that overran their deadline.
+// Bare errors are wrapped as TimeoutError.
+func Timeout(err error) Response {
+	if _, ok := errorTypeOf(err); !ok {
+		err = TimeoutError.Wrap(err)
+	}
+	return newResponse(http.StatusGatewayTimeout, err)
+}
+
 func newResponse(status int, body interface{}) Response {
-	return &response{
+	resp := &response{
 		status: status,
 		body:   body,
-		headers: map[string]string{
-			"Content-Type": "application/json",
+		headers: http.Header{
+			headerContentType: {contentTypeJSON},
 		},
 	}
+	if err, ok := body.(error); ok {
+		var p *Problem
+		if problemDetailsMode() || xerrors.As(err, &p) {
+			resp.problem = true
+			resp.headers.Set(headerContentType, contentTypeProblemJSON)
+		}
+	}
+	return resp
+}
+
+func newRedirectResponse(status int, location string) Response {
+	return newResponse(status, nil).WithHeader(headerLocation, location)
 }
+
+const (
+	contentTypeJSON        = "application/json"
+	contentTypeProblemJSON = "application/problem+json"
+
+	headerContentType = "Content-Type"
+	headerLocation    = "Location"
+	headerSetCookie   = "Set-Cookie"
+)
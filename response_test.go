@@ -48,6 +48,16 @@ func TestResponseStatus(t *testing.T) {
 			response: NotFound(fmt.Errorf("error")),
 			status:   http.StatusNotFound,
 		},
+		{
+			name:     "status method not allowed",
+			response: MethodNotAllowed(fmt.Errorf("error")),
+			status:   http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "status not acceptable",
+			response: NotAcceptable(fmt.Errorf("error")),
+			status:   http.StatusNotAcceptable,
+		},
 		{
 			name:     "status proxy auth required",
 			response: ProxyAuthRequired(fmt.Errorf("error")),
@@ -63,6 +73,61 @@ func TestResponseStatus(t *testing.T) {
 			response: InternalServerError(fmt.Errorf("error")),
 			status:   http.StatusInternalServerError,
 		},
+		{
+			name:     "status service unavailable",
+			response: ServiceUnavailable(fmt.Errorf("error")),
+			status:   http.StatusServiceUnavailable,
+		},
+		{
+			name:     "status accepted",
+			response: Accepted(`{"message": "OK"}`),
+			status:   http.StatusAccepted,
+		},
+		{
+			name:     "status forbidden",
+			response: Forbidden(fmt.Errorf("error")),
+			status:   http.StatusForbidden,
+		},
+		{
+			name:     "status gone",
+			response: Gone(fmt.Errorf("error")),
+			status:   http.StatusGone,
+		},
+		{
+			name:     "status precondition failed",
+			response: PreconditionFailed(fmt.Errorf("error")),
+			status:   http.StatusPreconditionFailed,
+		},
+		{
+			name:     "status request entity too large",
+			response: RequestEntityTooLarge(fmt.Errorf("error")),
+			status:   http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "status unprocessable entity",
+			response: UnprocessableEntity(fmt.Errorf("error")),
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "status too many requests",
+			response: TooManyRequests(fmt.Errorf("error")),
+			status:   http.StatusTooManyRequests,
+		},
+		{
+			name:     "status gateway timeout",
+			response: GatewayTimeout(fmt.Errorf("error")),
+			status:   http.StatusGatewayTimeout,
+		},
+		{
+			name:     "status timeout",
+			response: Timeout(fmt.Errorf("error")),
+			status:   http.StatusGatewayTimeout,
+		},
+		{
+			name:     "custom status",
+			response: Status(http.StatusTeapot, "I'm a teapot"),
+			status:   http.StatusTeapot,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			actual := test.response
@@ -115,6 +180,42 @@ func TestResponseBody(t *testing.T) {
 	})
 }
 
+func TestResponseRedirect(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		response Response
+		status   int
+	}{
+		{
+			name:     "moved permanently",
+			response: MovedPermanently("/events/123"),
+			status:   http.StatusMovedPermanently,
+		},
+		{
+			name:     "found",
+			response: Found("/events/123"),
+			status:   http.StatusFound,
+		},
+		{
+			name:     "temporary redirect",
+			response: TemporaryRedirect("/events/123"),
+			status:   http.StatusTemporaryRedirect,
+		},
+		{
+			name:     "permanent redirect",
+			response: PermanentRedirect("/events/123"),
+			status:   http.StatusPermanentRedirect,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			actual := test.response
+			assert.Equal(t, test.status, actual.Status())
+			assert.Equal(t, "/events/123", actual.Headers()["Location"])
+			assert.Nil(t, actual.Body())
+		})
+	}
+}
+
 func TestResponseCustomHeader(t *testing.T) {
 	actual := OK("OK").WithHeader("X-Custom-Id", "123")
 	assert.Equal(t, map[string]string{
@@ -122,3 +223,24 @@ func TestResponseCustomHeader(t *testing.T) {
 		"X-Custom-Id":  "123",
 	}, actual.Headers())
 }
+
+func TestResponseMultiValueHeader(t *testing.T) {
+	actual := OK("OK").
+		AddHeader("Vary", "Origin").
+		AddHeader("Vary", "Accept-Encoding").
+		WithCookie(&http.Cookie{Name: "session", Value: "abc", Path: "/"}).
+		WithCookie(&http.Cookie{Name: "theme", Value: "dark"}).
+		WithHeader("X-Custom-Id", "123").
+		DelHeader("X-Custom-Id")
+
+	assert.Equal(t, http.Header{
+		"Content-Type": {"application/json"},
+		"Vary":         {"Origin", "Accept-Encoding"},
+		"Set-Cookie":   {"session=abc; Path=/", "theme=dark"},
+	}, actual.HeaderValues())
+	assert.Equal(t, map[string]string{
+		"Content-Type": "application/json",
+		"Vary":         "Origin",
+		"Set-Cookie":   "session=abc; Path=/",
+	}, actual.Headers())
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gotech-labs/core/log"
+)
+
+// Health is the health check state flipped to unhealthy while the server is draining,
+// e.g. the health middleware.
+type Health interface {
+	SetHealthy(healthy bool)
+}
+
+func New(addr string, handler http.Handler) *server {
+	return &server{
+		httpServer: &http.Server{
+			Addr:    addr,
+			Handler: handler,
+		},
+		drainTimeout: defaultDrainTimeout,
+		logger:       log.New(os.Stdout),
+	}
+}
+
+type server struct {
+	httpServer    *http.Server
+	drainDelay    time.Duration
+	drainTimeout  time.Duration
+	health        []Health
+	shutdownHooks []func()
+	logger        *log.Logger
+}
+
+// Run serves http requests until SIGTERM or SIGINT is received, then shuts down gracefully.
+// A second signal while draining terminates the process immediately.
+func (s *server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// restore the default behavior, so that the next signal kills the process
+		stop()
+	}()
+	return s.Serve(ctx)
+}
+
+// Serve serves http requests until the context is done, then shuts down gracefully.
+func (s *server) Serve(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	s.logger.Info().Str("addr", s.httpServer.Addr).Msg("server started")
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		s.runShutdownHooks()
+		return err
+	case <-ctx.Done():
+	}
+	return s.shutdown()
+}
+
+func (s *server) shutdown() error {
+	defer s.runShutdownHooks()
+
+	s.logger.Info().Dur("timeout", s.drainTimeout).Msg("server draining")
+	for _, h := range s.health {
+		h.SetHealthy(false)
+	}
+	time.Sleep(s.drainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.logger.Error().Err(err).Msg("failed to shutdown server gracefully")
+		// close the connections still in flight after the drain timeout
+		_ = s.httpServer.Close()
+		return err
+	}
+	s.logger.Info().Msg("server stopped")
+	return nil
+}
+
+// runShutdownHooks calls the hooks in reverse registration order.
+func (s *server) runShutdownHooks() {
+	for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+		s.shutdownHooks[i]()
+	}
+}
+
+// WithDrainDelay sets the wait time between flipping the health to unhealthy and
+// closing the listener, so that load balancers stop routing new requests first.
+func (s *server) WithDrainDelay(delay time.Duration) *server {
+	s.drainDelay = delay
+	return s
+}
+
+// WithDrainTimeout sets the maximum time to wait for in-flight requests on shutdown.
+func (s *server) WithDrainTimeout(timeout time.Duration) *server {
+	s.drainTimeout = timeout
+	return s
+}
+
+func (s *server) WithHealth(health ...Health) *server {
+	s.health = append(s.health, health...)
+	return s
+}
+
+// WithShutdownHook registers hooks called after the server stopped, e.g. datadog.StopTracer.
+func (s *server) WithShutdownHook(hooks ...func()) *server {
+	s.shutdownHooks = append(s.shutdownHooks, hooks...)
+	return s
+}
+
+func (s *server) WithLogger(writer io.Writer) *server {
+	s.logger = log.New(writer)
+	return s
+}
+
+const (
+	defaultDrainTimeout = 30 * time.Second
+)
@@ -0,0 +1,144 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotech-labs/api"
+	apihttp "github.com/gotech-labs/api/http"
+	"github.com/gotech-labs/api/middleware/health"
+	. "github.com/gotech-labs/api/server"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("graceful shutdown", func(t *testing.T) {
+		var (
+			addr    = freeAddr(t)
+			hc      = health.New("/health", nil)
+			hooks   []string
+			handler = apihttp.NewHandler(func(ctx context.Context, req api.Request) api.Response {
+				return api.OK("ok")
+			}, hc.Middleware())
+			ctx, cancel = context.WithCancel(context.Background())
+			srv         = New(addr, handler).
+					WithLogger(bytes.NewBuffer(nil)).
+					WithDrainTimeout(time.Second).
+					WithHealth(hc).
+					WithShutdownHook(
+					func() { hooks = append(hooks, "first") },
+					func() { hooks = append(hooks, "second") },
+				)
+			done = make(chan error, 1)
+		)
+		go func() {
+			done <- srv.Serve(ctx)
+		}()
+		waitForServer(t, "http://"+addr+"/health")
+
+		cancel()
+		assert.NoError(t, <-done)
+		assert.False(t, hc.Healthy())
+		assert.Equal(t, []string{"second", "first"}, hooks)
+	})
+
+	t.Run("drain timeout", func(t *testing.T) {
+		var (
+			addr     = freeAddr(t)
+			started  = make(chan struct{})
+			canceled = make(chan struct{})
+			handler  = apihttp.NewHandler(func(ctx context.Context, req api.Request) api.Response {
+				if req.Path() == "/slow" {
+					close(started)
+					<-ctx.Done()
+					close(canceled)
+				}
+				return api.OK("ok")
+			})
+			ctx, cancel = context.WithCancel(context.Background())
+			srv         = New(addr, handler).
+					WithLogger(bytes.NewBuffer(nil)).
+					WithDrainTimeout(50 * time.Millisecond)
+			done = make(chan error, 1)
+		)
+		go func() {
+			done <- srv.Serve(ctx)
+		}()
+		waitForServer(t, "http://"+addr+"/")
+		go func() {
+			if resp, err := http.Get("http://" + addr + "/slow"); err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.DeadlineExceeded)
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("connection was not closed after the drain timeout")
+		}
+	})
+
+	t.Run("shutdown by signal", func(t *testing.T) {
+		var (
+			addr    = freeAddr(t)
+			stopped bool
+			handler = apihttp.NewHandler(func(ctx context.Context, req api.Request) api.Response {
+				return api.OK("ok")
+			})
+			srv = New(addr, handler).
+				WithLogger(bytes.NewBuffer(nil)).
+				WithShutdownHook(func() { stopped = true })
+			done = make(chan error, 1)
+		)
+		go func() {
+			done <- srv.Run()
+		}()
+		waitForServer(t, "http://"+addr+"/")
+
+		assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+		assert.NoError(t, <-done)
+		assert.True(t, stopped)
+	})
+
+	t.Run("listen error", func(t *testing.T) {
+		var (
+			stopped bool
+			srv     = New("invalid address", http.NotFoundHandler()).
+				WithLogger(bytes.NewBuffer(nil)).
+				WithShutdownHook(func() { stopped = true })
+		)
+		assert.Error(t, srv.Serve(context.Background()))
+		assert.True(t, stopped)
+	})
+}
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func waitForServer(t *testing.T, url string) {
+	for i := 0; i < 100; i++ {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server did not start: url=%v", url)
+}
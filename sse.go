@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event is a server-sent event.
+// ID and Event must not contain line breaks, which would inject fields into the stream.
+type Event struct {
+	ID    string
+	Event string
+	// Data is written as is when it is a string or []byte, otherwise it is encoded as JSON.
+	Data  interface{}
+	Retry time.Duration
+}
+
+// EventStreamResponse is a text/event-stream response.
+type EventStreamResponse interface {
+	StreamResponse
+	// WithHeartbeat sets the interval of the comment lines that keep the connection alive.
+	// Zero disables heartbeats.
+	WithHeartbeat(interval time.Duration) EventStreamResponse
+}
+
+// EventStream returns the server-sent events response that writes the events received from the channel.
+// The stream ends when the channel is closed or the context of the handler function is done.
+func EventStream(ctx context.Context, events <-chan Event) EventStreamResponse {
+	resp := &eventStreamResponse{
+		heartbeat: defaultHeartbeat,
+	}
+	resp.streamResponse = newStreamResponse(http.StatusOK, contentTypeEventStream, func(w io.Writer) error {
+		return resp.stream(ctx, w, events)
+	})
+	resp.headers.Set(headerCacheControl, "no-cache")
+	resp.headers.Set(headerXAccelBuffering, "no")
+	return resp
+}
+
+// EventStreamFunc returns the server-sent events response that writes the events returned by next.
+// The stream ends when next returns an error such as io.EOF or the context is done.
+func EventStreamFunc(ctx context.Context, next func(context.Context) (Event, error)) EventStreamResponse {
+	resp := EventStream(ctx, nil).(*eventStreamResponse)
+	resp.write = func(w io.Writer) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events := make(chan Event)
+		go func() {
+			defer close(events)
+			for {
+				event, err := next(ctx)
+				if err != nil {
+					return
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return resp.stream(ctx, w, events)
+	}
+	return resp
+}
+
+// eventStreamResponse is ...
+type eventStreamResponse struct {
+	*streamResponse
+	heartbeat time.Duration
+}
+
+// WithHeartbeat is ...
+func (r *eventStreamResponse) WithHeartbeat(interval time.Duration) EventStreamResponse {
+	r.heartbeat = interval
+	return r
+}
+
+// WithHeader is ...
+func (r *eventStreamResponse) WithHeader(key, value string) Response {
+	r.streamResponse.WithHeader(key, value)
+	return r
+}
+
+// AddHeader is ...
+func (r *eventStreamResponse) AddHeader(key, value string) Response {
+	r.streamResponse.AddHeader(key, value)
+	return r
+}
+
+// DelHeader is ...
+func (r *eventStreamResponse) DelHeader(key string) Response {
+	r.streamResponse.DelHeader(key)
+	return r
+}
+
+// WithCookie is ...
+func (r *eventStreamResponse) WithCookie(cookie *http.Cookie) Response {
+	r.streamResponse.WithCookie(cookie)
+	return r
+}
+
+func (r *eventStreamResponse) stream(ctx context.Context, w io.Writer, events <-chan Event) error {
+	var heartbeat <-chan time.Time
+	if r.heartbeat > 0 {
+		ticker := time.NewTicker(r.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := writeEvent(w, event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeEvent writes the event in the text/event-stream format in a single write,
+// so that the event is flushed to the client at once.
+func writeEvent(w io.Writer, event Event) error {
+	if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Event, "\r\n") {
+		return EncodeError.New(fmt.Sprintf(
+			"Failed to encode event: id=%q, event=%q contain line breaks", event.ID, event.Event))
+	}
+	buf := new(bytes.Buffer)
+	if event.ID != "" {
+		fmt.Fprintf(buf, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(buf, "event: %s\n", event.Event)
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(buf, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return JSONEncodeError.Wrapf(err,
+				"Failed to encode json object: error=%v", err.Error())
+		}
+		data = string(b)
+	}
+	for _, line := range strings.Split(lineBreaks.Replace(data), "\n") {
+		fmt.Fprintf(buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// lineBreaks normalizes the line breaks of the data, all of which end a field in the text/event-stream format.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+const (
+	defaultHeartbeat = 15 * time.Second
+
+	contentTypeEventStream = "text/event-stream"
+	headerCacheControl     = "Cache-Control"
+	headerXAccelBuffering  = "X-Accel-Buffering"
+)
@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+)
+
+func TestEventStream(t *testing.T) {
+	t.Run("channel", func(t *testing.T) {
+		events := make(chan Event, 3)
+		events <- Event{ID: "1", Event: "created", Data: map[string]int{"id": 1}}
+		events <- Event{Data: "first line\r\nsecond line\rthird line\nfourth line", Retry: 3 * time.Second}
+		events <- Event{ID: "3", Data: []byte("bytes")}
+		close(events)
+
+		actual := EventStream(context.Background(), events)
+		assert.Equal(t, http.StatusOK, actual.Status())
+		assert.Equal(t, "text/event-stream", actual.Headers()["Content-Type"])
+		assert.Equal(t, "no-cache", actual.Headers()["Cache-Control"])
+
+		buf := new(bytes.Buffer)
+		if assert.NoError(t, actual.Stream(buf)) {
+			assert.Equal(t, "id: 1\nevent: created\ndata: {\"id\":1}\n\n"+
+				"retry: 3000\ndata: first line\ndata: second line\ndata: third line\ndata: fourth line\n\n"+
+				"id: 3\ndata: bytes\n\n", buf.String())
+		}
+	})
+
+	t.Run("line breaks in fields", func(t *testing.T) {
+		for _, event := range []Event{
+			{ID: "1\ndata: injected", Data: "ok"},
+			{Event: "created\rretry: 0", Data: "ok"},
+		} {
+			events := make(chan Event, 1)
+			events <- event
+			close(events)
+
+			buf := new(bytes.Buffer)
+			assert.Error(t, EventStream(context.Background(), events).Stream(buf))
+			assert.Empty(t, buf.String())
+		}
+	})
+
+	t.Run("iterator", func(t *testing.T) {
+		count := 0
+		next := func(ctx context.Context) (Event, error) {
+			if count == 2 {
+				return Event{}, io.EOF
+			}
+			count++
+			return Event{Event: "tick", Data: "ok"}, nil
+		}
+		buf := new(bytes.Buffer)
+		if assert.NoError(t, EventStreamFunc(context.Background(), next).Stream(buf)) {
+			assert.Equal(t, "event: tick\ndata: ok\n\nevent: tick\ndata: ok\n\n", buf.String())
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		var (
+			ctx, cancel = context.WithCancel(context.Background())
+			events      = make(chan Event)
+			done        = make(chan error, 1)
+		)
+		go func() {
+			done <- EventStream(ctx, events).Stream(io.Discard)
+		}()
+		events <- Event{Data: "ok"}
+		cancel()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("stream did not stop")
+		}
+	})
+
+	t.Run("heartbeat", func(t *testing.T) {
+		var (
+			ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+			buf         = new(bytes.Buffer)
+		)
+		defer cancel()
+		err := EventStream(ctx, make(chan Event)).
+			WithHeartbeat(10 * time.Millisecond).
+			Stream(buf)
+		if assert.NoError(t, err) {
+			assert.Contains(t, buf.String(), ": heartbeat\n\n")
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// StreamResponse is a response whose body is written to the client without buffering.
+type StreamResponse interface {
+	Response
+	// Stream writes the body to the writer.
+	Stream(w io.Writer) error
+	// Seekable returns the content that supports range requests, or nil.
+	Seekable() (io.ReadSeeker, time.Time)
+	// Close closes the content when it implements io.Closer. It is called after the body has been written.
+	Close() error
+}
+
+// streamResponse is ...
+type streamResponse struct {
+	*response
+	write   func(io.Writer) error
+	content io.ReadSeeker
+	modtime time.Time
+	closer  io.Closer
+}
+
+// Stream returns the response that copies the reader to the client.
+// The body is sent with chunked transfer encoding unless Content-Length is set.
+// The response owns the reader, which is closed after the body has been written when it implements io.Closer.
+func Stream(status int, contentType string, reader io.Reader) StreamResponse {
+	resp := newStreamResponse(status, contentType, func(w io.Writer) error {
+		_, err := io.Copy(w, reader)
+		return err
+	})
+	resp.closer, _ = reader.(io.Closer)
+	return resp
+}
+
+// StreamFunc returns the response whose body is written by the callback, e.g. for large exports.
+func StreamFunc(status int, contentType string, write func(w io.Writer) error) StreamResponse {
+	return newStreamResponse(status, contentType, write)
+}
+
+// Attachment returns the file download response. Range requests are served from the content.
+// The response owns the content, e.g. *os.File, which is closed after the body has been written
+// when it implements io.Closer, so handlers must not close it.
+func Attachment(filename string, content io.ReadSeeker, modtime time.Time) StreamResponse {
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = contentTypeOctetStream
+	}
+	resp := newStreamResponse(http.StatusOK, contentType, func(w io.Writer) error {
+		_, err := io.Copy(w, content)
+		return err
+	})
+	resp.content = content
+	resp.modtime = modtime
+	resp.closer, _ = content.(io.Closer)
+	resp.headers.Set(headerContentDisposition,
+		mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	return resp
+}
+
+// Body is ...
+func (r *streamResponse) Body() interface{} {
+	return nil
+}
+
+// BodyJSON is ...
+func (r *streamResponse) BodyJSON() []byte {
+	return nil
+}
+
+// Stream is ...
+func (r *streamResponse) Stream(w io.Writer) error {
+	return r.write(w)
+}
+
+// Seekable is ...
+func (r *streamResponse) Seekable() (io.ReadSeeker, time.Time) {
+	return r.content, r.modtime
+}
+
+// Close is ...
+func (r *streamResponse) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// WithHeader is ...
+func (r *streamResponse) WithHeader(key, value string) Response {
+	r.response.WithHeader(key, value)
+	return r
+}
+
+// AddHeader is ...
+func (r *streamResponse) AddHeader(key, value string) Response {
+	r.response.AddHeader(key, value)
+	return r
+}
+
+// DelHeader is ...
+func (r *streamResponse) DelHeader(key string) Response {
+	r.response.DelHeader(key)
+	return r
+}
+
+// WithCookie is ...
+func (r *streamResponse) WithCookie(cookie *http.Cookie) Response {
+	r.response.WithCookie(cookie)
+	return r
+}
+
+// WithEncoding is ...
+func (r *streamResponse) WithEncoding(mediaType string) Response {
+	r.response.WithEncoding(mediaType)
+	return r
+}
+
+func newStreamResponse(status int, contentType string, write func(io.Writer) error) *streamResponse {
+	return &streamResponse{
+		response: &response{
+			status: status,
+			headers: http.Header{
+				headerContentType: {contentType},
+			},
+		},
+		write: write,
+	}
+}
+
+const (
+	contentTypeOctetStream   = "application/octet-stream"
+	headerContentDisposition = "Content-Disposition"
+)
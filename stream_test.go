@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+)
+
+func TestStreamResponse(t *testing.T) {
+	t.Run("reader", func(t *testing.T) {
+		actual := Stream(http.StatusOK, "text/plain", strings.NewReader("hello"))
+		assert.Equal(t, http.StatusOK, actual.Status())
+		assert.Equal(t, "text/plain", actual.Headers()["Content-Type"])
+		assert.Nil(t, actual.Body())
+		assert.Nil(t, actual.BodyJSON())
+
+		buf := new(bytes.Buffer)
+		if assert.NoError(t, actual.Stream(buf)) {
+			assert.Equal(t, "hello", buf.String())
+		}
+		content, _ := actual.Seekable()
+		assert.Nil(t, content)
+	})
+
+	t.Run("writer callback", func(t *testing.T) {
+		actual := StreamFunc(http.StatusOK, "text/csv", func(w io.Writer) error {
+			for i := 1; i <= 3; i++ {
+				if _, err := fmt.Fprintf(w, "%d\n", i); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		buf := new(bytes.Buffer)
+		if assert.NoError(t, actual.Stream(buf)) {
+			assert.Equal(t, "1\n2\n3\n", buf.String())
+		}
+	})
+
+	t.Run("attachment", func(t *testing.T) {
+		var (
+			modtime = time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC)
+			actual  = Attachment("events.csv", strings.NewReader("id\n1\n"), modtime)
+		)
+		assert.Equal(t, http.StatusOK, actual.Status())
+		assert.Equal(t, "text/csv; charset=utf-8", actual.Headers()["Content-Type"])
+		assert.Equal(t, `attachment; filename=events.csv`, actual.Headers()["Content-Disposition"])
+		content, actualModtime := actual.Seekable()
+		assert.NotNil(t, content)
+		assert.Equal(t, modtime, actualModtime)
+	})
+
+	t.Run("close content", func(t *testing.T) {
+		for _, test := range []struct {
+			name     string
+			response func(content *closer) StreamResponse
+		}{
+			{
+				name: "reader",
+				response: func(content *closer) StreamResponse {
+					return Stream(http.StatusOK, "text/plain", content)
+				},
+			},
+			{
+				name: "attachment",
+				response: func(content *closer) StreamResponse {
+					return Attachment("events.csv", content, time.Now())
+				},
+			},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				content := &closer{Reader: strings.NewReader("hello")}
+				actual := test.response(content)
+				assert.False(t, content.closed)
+				assert.NoError(t, actual.Close())
+				assert.True(t, content.closed)
+			})
+		}
+		assert.NoError(t, StreamFunc(http.StatusOK, "text/plain", func(w io.Writer) error {
+			return nil
+		}).Close())
+	})
+
+	t.Run("with header keeps stream response", func(t *testing.T) {
+		actual := Stream(http.StatusOK, "text/plain", strings.NewReader("hello")).
+			WithHeader("X-Custom-Id", "123")
+		_, ok := actual.(StreamResponse)
+		assert.True(t, ok)
+		assert.Equal(t, "123", actual.Headers()["X-Custom-Id"])
+	})
+}
+
+type closer struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
@@ -2,8 +2,12 @@ package testing
 
 import (
 	"bytes"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"net/url"
 
 	"github.com/gorilla/mux"
 )
@@ -15,19 +19,34 @@ type RequestBuilder struct {
 	Headers     map[string][]string
 	PathParams  map[string]string
 	QueryParams map[string]string
+	// FormValues is sent as application/x-www-form-urlencoded body, or as multipart/form-data with Files.
+	FormValues map[string][]string
+	Files      []File
+}
+
+// File is a file uploaded by the multipart/form-data request.
+type File struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Content     []byte
 }
 
 func (rb RequestBuilder) Build() *http.Request {
+	body, contentType := rb.body()
 	req := httptest.NewRequest(
 		rb.Method,
 		rb.Path,
-		bytes.NewBuffer(rb.Body),
+		bytes.NewBuffer(body),
 	)
 	if len(rb.PathParams) > 0 {
 		req = mux.SetURLVars(req, rb.PathParams)
 	}
 	if len(rb.Headers) > 0 {
-		req.Header = http.Header(rb.Headers)
+		req.Header = http.Header(rb.Headers).Clone()
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
 	if len(rb.QueryParams) > 0 {
 		values := req.URL.Query()
@@ -38,5 +57,51 @@ func (rb RequestBuilder) Build() *http.Request {
 	}
 	req.RemoteAddr = "127.0.0.1:80"
 	req.URL.Host = "localhost"
+	req.Host = "localhost"
 	return req
 }
+
+// body encodes the form values and files, or returns the raw body.
+func (rb RequestBuilder) body() ([]byte, string) {
+	if len(rb.Files) > 0 {
+		return rb.multipartBody()
+	}
+	if len(rb.FormValues) > 0 {
+		return []byte(url.Values(rb.FormValues).Encode()), "application/x-www-form-urlencoded"
+	}
+	return rb.Body, ""
+}
+
+func (rb RequestBuilder) multipartBody() ([]byte, string) {
+	var (
+		buf    = new(bytes.Buffer)
+		writer = multipart.NewWriter(buf)
+	)
+	for key, values := range rb.FormValues {
+		for _, value := range values {
+			if err := writer.WriteField(key, value); err != nil {
+				panic(err)
+			}
+		}
+	}
+	for _, file := range rb.Files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, file.Field, file.Filename))
+		if file.ContentType != "" {
+			header.Set("Content-Type", file.ContentType)
+		} else {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := part.Write(file.Content); err != nil {
+			panic(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes(), writer.FormDataContentType()
+}
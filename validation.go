@@ -0,0 +1,427 @@
+package api
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gotech-labs/core/errors"
+)
+
+// Validate checks the fields of the struct against their validate tags and returns
+// a ValidationError wrapping FieldErrors with every violation, or nil.
+//
+// Rules are separated by commas, e.g. validate:"required,min=1,max=100":
+//   - required: the value is not the zero value (nil pointers, empty strings and empty slices fail)
+//   - min=n, max=n: the number is within the range, or the length of strings (in runes), slices and maps is
+//   - len=n: the length of strings (in runes), slices and maps is exactly n
+//   - oneof=a b c: the value is one of the space separated values
+//   - email, uuid: the string is an email address or a UUID
+//   - regexp=pattern: the string matches the pattern. It must be the last rule, so that the pattern may contain commas
+//   - dive: the rules after dive are applied to each element of the slice or map
+//
+// Nested structs, and structs in slices and maps, are validated recursively.
+// Without the required rule, the other rules of nil pointers, empty strings, slices and maps are skipped.
+// Numbers are always validated, so optional numbers are declared as pointers.
+//
+// Rules of other validators sharing the tag, e.g. gte=0 or omitempty, are ignored. The rules of a type are
+// checked once when it is first validated, and invalid ones, e.g. min=a or email of a number, are returned
+// as an UnexpectedError whatever the values of the fields are.
+func Validate(obj interface{}) (err error) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if err := checkRules(v.Type()); err != nil {
+		return err
+	}
+	// the rules of interface fields depend on the values, so they are checked while validating
+	defer func() {
+		if r := recover(); r != nil {
+			err = invalidRuleError(r)
+		}
+	}()
+	var errs FieldErrors
+	validateValue(v, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.ValidationError.Wrapf(errs,
+		"Failed to validate request: %v", errs.Error())
+}
+
+func validateValue(v reflect.Value, name string, errs *FieldErrors) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			validateValue(v.Elem(), name, errs)
+		}
+	case reflect.Struct:
+		if _, ok := v.Interface().(time.Time); !ok {
+			validateStruct(v, name, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%v[%v]", name, i), errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(), fmt.Sprintf("%v[%v]", name, iter.Key()), errs)
+		}
+	}
+}
+
+func validateStruct(v reflect.Value, prefix string, errs *FieldErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous && fv.Kind() == reflect.Struct {
+			validateStruct(fv, prefix, errs)
+			continue
+		}
+		name, source := fieldName(f)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		rules := parseRules(f.Tag.Get("validate"))
+		if !validateRules(fv, name, source, rules, errs) {
+			continue
+		}
+		validateValue(fv, name, errs)
+	}
+}
+
+// validateRules applies the rules to the value and reports whether the value is worth validating further.
+func validateRules(v reflect.Value, name, source string, rules []string, errs *FieldErrors) bool {
+	for i, rule := range rules {
+		key, param := splitRule(rule)
+		if key == "required" {
+			if isEmpty(v) {
+				*errs = append(*errs, &FieldError{Field: name, Source: source, Reason: "required"})
+				return false
+			}
+			continue
+		}
+		if v.Kind() != reflect.Struct && !isNumber(v) && isEmpty(v) {
+			// absent optional values are not validated
+			return false
+		}
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if key == "dive" {
+			diveRules(v, name, source, rules[i+1:], errs)
+			return false
+		}
+		if reason := checkRule(v, key, param); reason != "" {
+			*errs = append(*errs, &FieldError{Field: name, Source: source, Reason: reason})
+			return false
+		}
+	}
+	return true
+}
+
+func diveRules(v reflect.Value, name, source string, rules []string, errs *FieldErrors) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elemName := fmt.Sprintf("%v[%v]", name, i)
+			if validateRules(v.Index(i), elemName, source, rules, errs) {
+				validateValue(v.Index(i), elemName, errs)
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elemName := fmt.Sprintf("%v[%v]", name, iter.Key())
+			if validateRules(iter.Value(), elemName, source, rules, errs) {
+				validateValue(iter.Value(), elemName, errs)
+			}
+		}
+	default:
+		panic(invalidRule(fmt.Sprintf("Invalid validation rule: field=%v, rule=dive, type=%v", name, v.Type())))
+	}
+}
+
+// checkRule returns the reason of the violation, or an empty string. Unknown rules are ignored.
+func checkRule(v reflect.Value, key, param string) string {
+	switch key {
+	case "min":
+		if compare(v, key, param) < 0 {
+			return fmt.Sprintf("must be at least %v", param)
+		}
+	case "max":
+		if compare(v, key, param) > 0 {
+			return fmt.Sprintf("must be at most %v", param)
+		}
+	case "len":
+		if !hasLength(v) {
+			panic(invalidRule(fmt.Sprintf("Invalid validation rule: rule=%v, type=%v", key, v.Type())))
+		}
+		if compare(v, key, param) != 0 {
+			return fmt.Sprintf("must have length %v", param)
+		}
+	case "oneof":
+		value := fmt.Sprint(v.Interface())
+		for _, candidate := range strings.Fields(param) {
+			if value == candidate {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%v]", param)
+	case "email":
+		addr, err := mail.ParseAddress(stringValue(v, key))
+		if err != nil || addr.Address != v.String() {
+			return "must be a valid email address"
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(stringValue(v, key)) {
+			return "must be a valid uuid"
+		}
+	case "regexp":
+		if !compileRule(param).MatchString(stringValue(v, key)) {
+			return fmt.Sprintf("must match %v", param)
+		}
+	}
+	return ""
+}
+
+// compare compares the number, or the length of strings, slices and maps, with the param.
+func compare(v reflect.Value, key, param string) int {
+	var value, limit float64
+	if hasLength(v) {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			panic(invalidRule(fmt.Sprintf("Invalid validation rule: rule=%v, param=%v", key, param)))
+		}
+		value, limit = float64(length(v)), float64(n)
+	} else {
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(invalidRule(fmt.Sprintf("Invalid validation rule: rule=%v, param=%v", key, param)))
+		}
+		limit = n
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			value = v.Float()
+		default:
+			panic(invalidRule(fmt.Sprintf("Invalid validation rule: rule=%v, type=%v", key, v.Type())))
+		}
+	}
+	switch {
+	case value < limit:
+		return -1
+	case value > limit:
+		return 1
+	}
+	return 0
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func hasLength(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func length(v reflect.Value) int {
+	if v.Kind() == reflect.String {
+		return utf8.RuneCountInString(v.String())
+	}
+	return v.Len()
+}
+
+func stringValue(v reflect.Value, key string) string {
+	if v.Kind() != reflect.String {
+		panic(invalidRule(fmt.Sprintf("Invalid validation rule: rule=%v, type=%v", key, v.Type())))
+	}
+	return v.String()
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// fieldName returns the name of the field in the request and where it is bound from.
+func fieldName(f reflect.StructField) (string, string) {
+	for _, source := range paramSources {
+		if name, ok := f.Tag.Lookup(source); ok && name != "" {
+			return name, source
+		}
+	}
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name, ""
+		}
+	}
+	return f.Name, ""
+}
+
+// checkRules checks the rules of the type and its nested types once, by applying them to zero values.
+func checkRules(t reflect.Type) (err error) {
+	if checked, ok := checkedTypes.Load(t); ok {
+		err, _ = checked.(error)
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = invalidRuleError(r)
+		}
+		checkedTypes.Store(t, err)
+	}()
+	checkType(t, map[reflect.Type]bool{})
+	return nil
+}
+
+func checkType(t reflect.Type, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == timeType || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if name, _ := fieldName(f); name != "-" {
+				checkFieldRules(f.Type, name, parseRules(f.Tag.Get("validate")))
+				checkType(f.Type, seen)
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		checkType(t.Elem(), seen)
+	}
+}
+
+func checkFieldRules(t reflect.Type, name string, rules []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return
+	}
+	for i, rule := range rules {
+		key, param := splitRule(rule)
+		switch key {
+		case "required":
+		case "dive":
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				checkFieldRules(t.Elem(), name, rules[i+1:])
+			default:
+				panic(invalidRule(fmt.Sprintf("Invalid validation rule: field=%v, rule=dive, type=%v", name, t)))
+			}
+			return
+		default:
+			_ = checkRule(reflect.Zero(t), key, param)
+		}
+	}
+}
+
+// invalidRule is the panic of the rules that can not be applied to the field.
+type invalidRule string
+
+func invalidRuleError(r interface{}) error {
+	rule, ok := r.(invalidRule)
+	if !ok {
+		panic(r)
+	}
+	return errors.UnexpectedError.New(string(rule))
+}
+
+func splitRule(rule string) (string, string) {
+	if i := strings.Index(rule, "="); i >= 0 {
+		return rule[:i], rule[i+1:]
+	}
+	return rule, ""
+}
+
+// parseRules splits the validate tag into rules. The regexp rule takes the rest of the tag.
+func parseRules(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "regexp=") {
+			return append(rules, tag)
+		}
+		rule := tag
+		if i := strings.Index(tag, ","); i >= 0 {
+			rule, tag = tag[:i], tag[i+1:]
+		} else {
+			tag = ""
+		}
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func compileRule(pattern string) *regexp.Regexp {
+	if re, ok := rulePatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(invalidRule(fmt.Sprintf("Invalid validation rule: rule=regexp, param=%v", pattern)))
+	}
+	rulePatterns.Store(pattern, re)
+	return re
+}
+
+var (
+	checkedTypes sync.Map
+	timeType     = reflect.TypeOf(time.Time{})
+	rulePatterns sync.Map
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
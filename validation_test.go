@@ -0,0 +1,208 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+)
+
+func TestValidate(t *testing.T) {
+	type Address struct {
+		City    string `json:"city" validate:"required"`
+		ZipCode string `json:"zip_code" validate:"regexp=^[0-9]{3}-[0-9]{4}$"`
+	}
+	type Input struct {
+		ID        string            `path:"id" validate:"uuid"`
+		Name      string            `json:"name" validate:"required,max=10"`
+		Age       int               `json:"age" validate:"min=0,max=150"`
+		Email     *string           `json:"email" validate:"email"`
+		Code      string            `json:"code" validate:"len=3"`
+		Status    string            `json:"status" validate:"oneof=active inactive"`
+		Tags      []string          `json:"tags" validate:"max=2,dive,min=2"`
+		Address   Address           `json:"address"`
+		Addresses []*Address        `json:"addresses" validate:"required"`
+		Labels    map[string]string `json:"labels" validate:"dive,oneof=a b"`
+	}
+	var (
+		email   = "mj@example.com"
+		invalid = "michael"
+		valid   = func() Input {
+			return Input{
+				ID:        "0d5d1c54-5b2f-4a43-9c3f-76a2f0e6e7a1",
+				Name:      "Michael",
+				Age:       59,
+				Email:     &email,
+				Code:      "abc",
+				Status:    "active",
+				Tags:      []string{"nba", "bulls"},
+				Address:   Address{City: "Chicago", ZipCode: "123-4567"},
+				Addresses: []*Address{{City: "Chicago"}},
+				Labels:    map[string]string{"key": "a"},
+			}
+		}
+	)
+
+	for _, tc := range []struct {
+		name     string
+		input    func() Input
+		expected string
+	}{
+		{
+			name:  "valid",
+			input: valid,
+		},
+		{
+			name: "valid (optional pointer)",
+			input: func() Input {
+				in := valid()
+				in.Email = nil
+				return in
+			},
+		},
+		{
+			name: "required",
+			input: func() Input {
+				in := valid()
+				in.Name = ""
+				in.Addresses = nil
+				return in
+			},
+			expected: "field=name, reason=required; field=addresses, reason=required",
+		},
+		{
+			name: "min and max",
+			input: func() Input {
+				in := valid()
+				in.Name = "Michael Jeffrey Jordan"
+				in.Age = -1
+				in.Tags = []string{"a", "b", "c"}
+				return in
+			},
+			expected: "field=name, reason=must be at most 10; field=age, reason=must be at least 0; field=tags, reason=must be at most 2",
+		},
+		{
+			name: "len, oneof, email and uuid",
+			input: func() Input {
+				in := valid()
+				in.ID = "123"
+				in.Code = "abcd"
+				in.Status = "deleted"
+				in.Email = &invalid
+				return in
+			},
+			expected: "field=id, source=path, reason=must be a valid uuid; " +
+				"field=email, reason=must be a valid email address; " +
+				"field=code, reason=must have length 3; " +
+				"field=status, reason=must be one of [active inactive]",
+		},
+		{
+			name: "nested and dive",
+			input: func() Input {
+				in := valid()
+				in.Tags = []string{"nba", "b"}
+				in.Address = Address{ZipCode: "1234567"}
+				in.Addresses = []*Address{{City: "Chicago"}, {}}
+				in.Labels = map[string]string{"key": "c"}
+				return in
+			},
+			expected: "field=tags[1], reason=must be at least 2; " +
+				"field=address.city, reason=required; " +
+				"field=address.zip_code, reason=must match ^[0-9]{3}-[0-9]{4}$; " +
+				"field=addresses[1].city, reason=required; " +
+				"field=labels[key], reason=must be one of [a b]",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			input := tc.input()
+			err := Validate(&input)
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Equal(t, "Failed to validate request: "+tc.expected, err.Error())
+				assert.Equal(t, http.StatusBadRequest, ErrorStatus(err))
+			}
+		})
+	}
+
+	t.Run("not struct", func(t *testing.T) {
+		assert.NoError(t, Validate(map[string]interface{}{"key": "value"}))
+		assert.NoError(t, Validate(nil))
+	})
+
+	t.Run("rules of other validators", func(t *testing.T) {
+		input := struct {
+			Name string `validate:"omitempty,required,gte=1"`
+			Age  int    `validate:"gte=0,min=0"`
+		}{Name: "name", Age: -1}
+		err := Validate(&input)
+		if assert.Error(t, err) {
+			assert.Equal(t, "Failed to validate request: field=Age, reason=must be at least 0", err.Error())
+		}
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		for _, input := range []interface{}{
+			&struct {
+				Age int `validate:"min=a"`
+			}{Age: 1},
+			&struct {
+				Email *int `validate:"email"`
+			}{},
+			&struct {
+				Items []struct {
+					Name string `validate:"len=x"`
+				}
+			}{},
+			&struct {
+				Code string `validate:"regexp=["`
+			}{},
+			&struct {
+				Value interface{} `validate:"max=1"`
+			}{Value: true},
+		} {
+			err := Validate(input)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "Invalid validation rule")
+				assert.Equal(t, http.StatusInternalServerError, ErrorStatus(err))
+			}
+		}
+	})
+}
+
+func TestValidationErrorResponse(t *testing.T) {
+	input := struct {
+		Name string `json:"name" validate:"required"`
+		Age  int    `json:"age" validate:"min=0"`
+	}{Age: -1}
+	err := Validate(&input)
+
+	t.Run("json", func(t *testing.T) {
+		resp := BadRequest(err)
+		assert.JSONEq(t, `{
+			"message": "Failed to validate request: field=name, reason=required; field=age, reason=must be at least 0",
+			"errors": [
+				{"field": "name", "reason": "required"},
+				{"field": "age", "reason": "must be at least 0"}
+			]
+		}`, string(resp.BodyJSON()))
+	})
+
+	t.Run("problem details", func(t *testing.T) {
+		UseProblemDetails(true)
+		defer UseProblemDetails(false)
+
+		resp := BadRequest(err)
+		body := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(resp.BodyJSON(), &body))
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"field": "name", "reason": "required"},
+			map[string]interface{}{"field": "age", "reason": "must be at least 0"},
+		}, body["errors"])
+	})
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MessageType is the type of WebSocket data messages.
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+// Close codes defined by RFC 6455 section 7.4.1.
+const (
+	CloseNormalClosure       = 1000
+	CloseGoingAway           = 1001
+	CloseProtocolError       = 1002
+	CloseUnsupportedData     = 1003
+	CloseNoStatusReceived    = 1005
+	CloseAbnormalClosure     = 1006
+	CloseInvalidPayload      = 1007
+	ClosePolicyViolation     = 1008
+	CloseMessageTooBig       = 1009
+	CloseInternalServerError = 1011
+)
+
+// WebSocketConn is a WebSocket connection passed to the WebSocketHandler.
+// Pings from the client are answered automatically while reading messages.
+type WebSocketConn interface {
+	// ReadMessage blocks until a data message arrives. It returns *CloseError
+	// when the connection is closed by the client.
+	ReadMessage() (MessageType, []byte, error)
+	WriteMessage(messageType MessageType, data []byte) error
+	Ping(data []byte) error
+	// Close sends the close frame with the code and closes the connection.
+	Close(code int, reason string) error
+	Subprotocol() string
+}
+
+// WebSocketHandler handles the upgraded connection. The context is done when the connection is
+// closed or the request context is canceled. Returning an error closes the connection with 1011.
+type WebSocketHandler func(ctx context.Context, conn WebSocketConn) error
+
+// CloseError is the error returned when the connection is closed.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed: code=%v, reason=%v", e.Code, e.Reason)
+}
+
+// UpgradeResponse is the 101 Switching Protocols response that upgrades the connection to WebSocket.
+type UpgradeResponse interface {
+	Response
+	WebSocketHandler() WebSocketHandler
+	// Context returns the context of the handler, which the WebSocketHandler is called with.
+	Context() context.Context
+}
+
+// WebSocket returns the response that upgrades the request to a WebSocket connection handled by the handler.
+// The handler is called with the context derived from ctx, so that it keeps the values put by middlewares,
+// e.g. the request id and logger. Invalid handshake requests are responded as bad request.
+// When subprotocols are given, the first one requested by the client is selected.
+func WebSocket(ctx context.Context, req Request, handler WebSocketHandler, subprotocols ...string) Response {
+	if !headerContainsToken(req.Header(headerConnection), "upgrade") ||
+		!headerContainsToken(req.Header(headerUpgrade), "websocket") {
+		return BadRequest(WebSocketError.New("Invalid upgrade request: missing websocket upgrade headers"))
+	}
+	if v := firstHeader(req, headerSecWebSocketVersion); v != "13" {
+		return BadRequest(WebSocketError.New(fmt.Sprintf(
+			"Invalid upgrade request: unsupported version=%v", v))).
+			WithHeader(headerSecWebSocketVersion, "13")
+	}
+	key := firstHeader(req, headerSecWebSocketKey)
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return BadRequest(WebSocketError.New(fmt.Sprintf(
+			"Invalid upgrade request: key=%v", key)))
+	}
+	resp := &upgradeResponse{
+		response: &response{
+			status:  http.StatusSwitchingProtocols,
+			headers: http.Header{},
+		},
+		handler: handler,
+		ctx:     ctx,
+	}
+	resp.headers.Set(headerUpgrade, "websocket")
+	resp.headers.Set(headerConnection, "Upgrade")
+	resp.headers.Set(headerSecWebSocketAccept, websocketAccept(key))
+	if protocol := selectSubprotocol(req.Header(headerSecWebSocketProtocol), subprotocols); protocol != "" {
+		resp.headers.Set(headerSecWebSocketProtocol, protocol)
+	}
+	return resp
+}
+
+// upgradeResponse is ...
+type upgradeResponse struct {
+	*response
+	handler WebSocketHandler
+	ctx     context.Context
+}
+
+// Body is ...
+func (r *upgradeResponse) Body() interface{} {
+	return nil
+}
+
+// BodyJSON is ...
+func (r *upgradeResponse) BodyJSON() []byte {
+	return nil
+}
+
+// WebSocketHandler is ...
+func (r *upgradeResponse) WebSocketHandler() WebSocketHandler {
+	return r.handler
+}
+
+// Context is ...
+func (r *upgradeResponse) Context() context.Context {
+	return r.ctx
+}
+
+// WithHeader is ...
+func (r *upgradeResponse) WithHeader(key, value string) Response {
+	r.response.WithHeader(key, value)
+	return r
+}
+
+// AddHeader is ...
+func (r *upgradeResponse) AddHeader(key, value string) Response {
+	r.response.AddHeader(key, value)
+	return r
+}
+
+// DelHeader is ...
+func (r *upgradeResponse) DelHeader(key string) Response {
+	r.response.DelHeader(key)
+	return r
+}
+
+// WithCookie is ...
+func (r *upgradeResponse) WithCookie(cookie *http.Cookie) Response {
+	r.response.WithCookie(cookie)
+	return r
+}
+
+// WithEncoding is ...
+func (r *upgradeResponse) WithEncoding(mediaType string) Response {
+	r.response.WithEncoding(mediaType)
+	return r
+}
+
+// websocketAccept computes Sec-WebSocket-Accept from Sec-WebSocket-Key (RFC 6455 section 4.2.2).
+func websocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func selectSubprotocol(requested []string, supported []string) string {
+	for _, values := range requested {
+		for _, protocol := range strings.Split(values, ",") {
+			protocol = strings.TrimSpace(protocol)
+			for _, s := range supported {
+				if s == protocol {
+					return protocol
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func headerContainsToken(values []string, token string) bool {
+	for _, value := range values {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firstHeader(req Request, key string) string {
+	if values := req.Header(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	headerConnection           = "Connection"
+	headerUpgrade              = "Upgrade"
+	headerSecWebSocketKey      = "Sec-WebSocket-Key"
+	headerSecWebSocketVersion  = "Sec-WebSocket-Version"
+	headerSecWebSocketAccept   = "Sec-WebSocket-Accept"
+	headerSecWebSocketProtocol = "Sec-WebSocket-Protocol"
+)
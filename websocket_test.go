@@ -0,0 +1,92 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/gotech-labs/api"
+	apitest "github.com/gotech-labs/api/http/testing"
+)
+
+func TestWebSocket(t *testing.T) {
+	handler := func(ctx context.Context, conn WebSocketConn) error {
+		return nil
+	}
+	for _, test := range []struct {
+		name    string
+		headers map[string][]string
+		status  int
+		accept  string
+		proto   string
+	}{
+		{
+			name: "upgrade",
+			headers: map[string][]string{
+				"Connection":             {"keep-alive, Upgrade"},
+				"Upgrade":                {"websocket"},
+				"Sec-Websocket-Version":  {"13"},
+				"Sec-Websocket-Key":      {"dGhlIHNhbXBsZSBub25jZQ=="},
+				"Sec-Websocket-Protocol": {"chat.v2, chat.v1"},
+			},
+			status: http.StatusSwitchingProtocols,
+			accept: "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=",
+			proto:  "chat.v2",
+		},
+		{
+			name: "missing upgrade header",
+			headers: map[string][]string{
+				"Sec-Websocket-Version": {"13"},
+				"Sec-Websocket-Key":     {"dGhlIHNhbXBsZSBub25jZQ=="},
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "unsupported version",
+			headers: map[string][]string{
+				"Connection":            {"Upgrade"},
+				"Upgrade":               {"websocket"},
+				"Sec-Websocket-Version": {"8"},
+				"Sec-Websocket-Key":     {"dGhlIHNhbXBsZSBub25jZQ=="},
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "invalid key",
+			headers: map[string][]string{
+				"Connection":            {"Upgrade"},
+				"Upgrade":               {"websocket"},
+				"Sec-Websocket-Version": {"13"},
+				"Sec-Websocket-Key":     {"invalid"},
+			},
+			status: http.StatusBadRequest,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := apitest.RequestBuilder{
+				Method:  http.MethodGet,
+				Path:    "/ws",
+				Headers: test.headers,
+			}.Build()
+			ctx := context.WithValue(context.Background(), contextKey{}, "value")
+			actual := WebSocket(ctx, req, handler, "chat.v1", "chat.v2")
+			assert.Equal(t, test.status, actual.Status())
+			if test.status == http.StatusSwitchingProtocols {
+				ur, ok := actual.(UpgradeResponse)
+				if assert.True(t, ok) {
+					assert.NotNil(t, ur.WebSocketHandler())
+					assert.Equal(t, "value", ur.Context().Value(contextKey{}))
+				}
+				_, ok = actual.WithEncoding("application/json").(UpgradeResponse)
+				assert.True(t, ok)
+				assert.Equal(t, test.accept, actual.Headers()["Sec-Websocket-Accept"])
+				assert.Equal(t, test.proto, actual.Headers()["Sec-Websocket-Protocol"])
+				assert.Nil(t, actual.Body())
+			}
+		})
+	}
+}
+
+type contextKey struct{}